@@ -0,0 +1,166 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// GetAgenda returns the events of a day, work week or week grouped by day in
+// the user's time zone, with multi-day events repeated on each of their days
+// and overlapping timed events flagged
+func GetAgenda(ctx context.Context, provider CalendarProvider, input AgendaInput) (AgendaOutput, error) {
+	return getAgenda(ctx, provider, input, time.Now())
+}
+
+func getAgenda(ctx context.Context, provider CalendarProvider, input AgendaInput, now time.Time) (AgendaOutput, error) {
+	accounts, err := getTargetAccounts(provider, input.Account)
+	if err != nil {
+		return AgendaOutput{}, err
+	}
+	zones, err := resolveUserZones(ctx, provider, accounts, input.TimeZone)
+	if err != nil {
+		return AgendaOutput{}, err
+	}
+	loc := zones.display
+
+	day := startOfDay(now.In(loc))
+	if strings.TrimSpace(input.Date) != "" {
+		span, err := parseTimeExpr(input.Date, now, now, loc, zones.firstDay)
+		if err != nil {
+			return AgendaOutput{}, fmt.Errorf("invalid date format: %w", err)
+		}
+		day = startOfDay(span.Start)
+	}
+
+	view := cmp.Or(strings.ToLower(strings.TrimSpace(input.View)), "day")
+	var start time.Time
+	var numDays int
+	switch view {
+	case "day":
+		start, numDays = day, 1
+	case "work_week":
+		// Monday to Friday, within the week of the day
+		week := weekStart(day, zones.firstDay)
+		start, numDays = week.AddDate(0, 0, (int(time.Monday)-int(zones.firstDay)+7)%7), 5
+	case "week":
+		start, numDays = weekStart(day, zones.firstDay), 7
+	default:
+		return AgendaOutput{}, fmt.Errorf("invalid view '%s' (expected day, work_week or week)", input.View)
+	}
+	end := start.AddDate(0, 0, numDays)
+
+	events, err := listEvents(ctx, provider, ListEventsInput{
+		Account:         input.Account,
+		CalendarID:      input.CalendarID,
+		CalendarIDs:     input.CalendarIDs,
+		AllCalendars:    input.AllCalendars,
+		TimeMin:         start.Format(time.RFC3339),
+		TimeMax:         end.Format(time.RFC3339),
+		MaxResults:      maxListResults,
+		ExcludeDeclined: !input.IncludeDeclined,
+	}, accounts, zones, maxListResults)
+	if err != nil {
+		return AgendaOutput{}, err
+	}
+
+	return AgendaOutput{
+		View:      view,
+		TimeMin:   start,
+		TimeMax:   end,
+		TimeZone:  events.TimeZone,
+		Days:      agendaDays(events.Events, start, numDays),
+		Truncated: events.Truncated,
+		Errors:    events.Errors,
+	}, nil
+}
+
+// agendaDays groups chronologically sorted events into numDays days from the
+// given midnight. Events spanning several days appear on each of them.
+func agendaDays(events []Event, start time.Time, numDays int) []AgendaDay {
+	overlaps := eventOverlaps(events)
+
+	days := make([]AgendaDay, numDays)
+	for i := range days {
+		dayStart := start.AddDate(0, 0, i)
+		days[i] = AgendaDay{
+			Date:    dayStart.Format("2006-01-02"),
+			Weekday: dayStart.Weekday().String(),
+			Items:   []AgendaItem{},
+		}
+
+		for j, ev := range events {
+			first, last := eventDays(ev, start.Location())
+			if first.After(dayStart) || last.Before(dayStart) {
+				continue
+			}
+			item := AgendaItem{Event: ev, Overlaps: overlaps[j]}
+			if total := daysBetween(first, last) + 1; total > 1 {
+				item.Day = daysBetween(first, dayStart) + 1
+				item.Days = total
+			}
+			days[i].Items = append(days[i].Items, item)
+		}
+	}
+	return days
+}
+
+// eventDays returns the midnights of the first and last days of an event in
+// loc. All-day events keep their own dates; the end of an event is exclusive.
+func eventDays(ev Event, loc *time.Location) (time.Time, time.Time) {
+	if ev.AllDay {
+		first := dateIn(ev.Start, loc)
+		last := dateIn(ev.End, loc).AddDate(0, 0, -1)
+		if last.Before(first) {
+			last = first
+		}
+		return first, last
+	}
+
+	first := startOfDay(ev.Start.In(loc))
+	if !ev.End.After(ev.Start) {
+		return first, first
+	}
+	return first, startOfDay(ev.End.Add(-time.Nanosecond).In(loc))
+}
+
+// eventOverlaps returns, for each event that blocks time, the other busy
+// timed events it overlaps. All-day and free events never conflict, and
+// copies of the same meeting in several calendars do not overlap each other
+// and are listed once.
+func eventOverlaps(events []Event) [][]EventRef {
+	busy := busyEvents(events, false)
+	overlaps := make([][]EventRef, len(events))
+	for i, a := range events {
+		if !blocksTime(a, false) {
+			continue
+		}
+		for _, b := range busy {
+			if sameMeeting(a, b) {
+				continue
+			}
+			if a.Start.Before(b.End) && b.Start.Before(a.End) {
+				overlaps[i] = append(overlaps[i], refOf(b))
+			}
+		}
+	}
+	return overlaps
+}
+
+// refOf returns the reference of an event
+func refOf(ev Event) EventRef {
+	return EventRef{Account: ev.Account, CalendarID: ev.CalendarID, EventID: ev.ID, Summary: ev.Summary}
+}
+
+// dateIn returns midnight in loc of the date of t in its own location
+func dateIn(t time.Time, loc *time.Location) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, loc)
+}
+
+// daysBetween returns the number of calendar days from one midnight to another
+func daysBetween(from, to time.Time) int {
+	return int(dateIn(to, time.UTC).Sub(dateIn(from, time.UTC)) / (24 * time.Hour))
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// TestAgendaDays verifies overnight events span both days and overlaps are
+// only flagged between timed events
+func TestAgendaDays(t *testing.T) {
+	events := []Event{
+		{ID: "holiday", AllDay: true, Start: utcAt(2, 0, 0), End: utcAt(3, 0, 0)},
+		{ID: "flight", Start: utcAt(2, 22, 0), End: utcAt(3, 2, 0)},
+		{ID: "call", Start: utcAt(2, 23, 0), End: utcAt(2, 23, 30)},
+		{ID: "late", Start: utcAt(3, 23, 0), End: utcAt(4, 0, 0)},
+	}
+	days := agendaDays(events, utcAt(2, 0, 0), 3)
+
+	var got [][]string
+	for _, day := range days {
+		var ids []string
+		for _, item := range day.Items {
+			ids = append(ids, item.Event.ID)
+		}
+		got = append(got, ids)
+	}
+	want := [][]string{{"holiday", "flight", "call"}, {"flight", "late"}, nil}
+	if !slices.EqualFunc(got, want, slices.Equal) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+
+	flight := days[1].Items[0]
+	if flight.Day != 2 || flight.Days != 2 || agendaTime(flight) != "until 02:00" {
+		t.Errorf("Expected the end of the flight on day 2 of 2, got %+v (%s)", flight, agendaTime(flight))
+	}
+	if got := days[0].Items[1].Overlaps; !slices.Equal(got, []EventRef{{EventID: "call"}}) {
+		t.Errorf("Expected the flight to overlap the call only, got %v", got)
+	}
+	if days[0].Items[0].Overlaps != nil || days[1].Items[1].Days != 0 {
+		t.Errorf("Unexpected items %+v", days)
+	}
+	if days[2].Items == nil || days[2].Date != "2026-02-04" || days[2].Weekday != time.Wednesday.String() {
+		t.Errorf("Expected an empty Wednesday, got %+v", days[2])
+	}
+}
+
+// TestEventOverlaps verifies copies of a meeting do not overlap each other,
+// free events are ignored and events are told apart by account and calendar
+func TestEventOverlaps(t *testing.T) {
+	events := []Event{
+		{Account: "work", CalendarID: "primary", ID: "sync", ICalUID: "sync@google.com", Start: utcAt(2, 10, 0), End: utcAt(2, 11, 0)},
+		{Account: "personal", CalendarID: "primary", ID: "sync", ICalUID: "sync@google.com", Start: utcAt(2, 10, 0), End: utcAt(2, 11, 0)},
+		{Account: "work", CalendarID: "team", ID: "review", Start: utcAt(2, 10, 30), End: utcAt(2, 11, 30)},
+		{Account: "work", CalendarID: "primary", ID: "gym", Transparency: "transparent", Start: utcAt(2, 10, 0), End: utcAt(2, 12, 0)},
+	}
+	overlaps := eventOverlaps(events)
+
+	review := EventRef{Account: "work", CalendarID: "team", EventID: "review"}
+	sync := EventRef{Account: "work", CalendarID: "primary", EventID: "sync"}
+	want := [][]EventRef{{review}, {review}, {sync}, nil}
+	if !slices.EqualFunc(overlaps, want, slices.Equal) {
+		t.Errorf("Expected %v, got %v", want, overlaps)
+	}
+}
@@ -1,246 +1,780 @@
 package main
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
 	"time"
+)
+
+// accessRoles are the access roles of calendars, from the least to the most access
+var accessRoles = []string{"freeBusyReader", "reader", "writer", "owner"}
+
+const (
+	// maxConcurrentAccounts bounds how many accounts are queried at once
+	maxConcurrentAccounts = 4
 
-	"google.golang.org/api/calendar/v3"
+	// accountTimeout bounds the time spent querying a single account
+	accountTimeout = 30 * time.Second
+
+	// maxListResults bounds the events a listing tool returns at once
+	maxListResults = 250
 )
 
-// GetCalendars returns all calendars for the specified account (or all accounts if empty)
-func GetCalendars(ctx context.Context, accountName string) ([]Calendar, error) {
-	accounts, err := getTargetAccounts(accountName)
+// GetCalendars returns the calendars for the specified account (or all accounts if empty).
+// Accounts that fail are reported in the output's Errors; an error is only
+// returned if no account could be queried.
+func GetCalendars(ctx context.Context, provider CalendarProvider, input ListCalendarsInput) (ListCalendarsOutput, error) {
+	accounts, err := getTargetAccounts(provider, input.Account)
 	if err != nil {
-		return nil, err
+		return ListCalendarsOutput{}, err
+	}
+
+	maxResults := input.MaxResults
+	if maxResults <= 0 {
+		maxResults = 250
+	}
+	if maxResults > 1000 {
+		maxResults = 1000
 	}
 
-	var calendars []Calendar
-	for _, acc := range accounts {
-		srv, err := GetCalendarService(ctx, acc)
+	if input.MinAccessRole != "" && !slices.Contains(accessRoles, input.MinAccessRole) {
+		return ListCalendarsOutput{}, fmt.Errorf("invalid min_access_role '%s' (expected %s)", input.MinAccessRole, strings.Join(accessRoles, ", "))
+	}
+
+	// A cursor continues each account's calendar list where the previous call
+	// stopped, leaving out the accounts that had no calendars left
+	now := time.Now()
+	query := input
+	query.Cursor, query.MaxResults = "", 0
+	var positions map[string]listPosition
+	if input.Cursor != "" {
+		cursor, err := decodeCursor(input.Cursor, "calendars", queryHash(query), now)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get service for account '%s': %w", acc, err)
+			return ListCalendarsOutput{}, err
 		}
+		positions = cursor.Lists
+		accounts = slices.DeleteFunc(accounts, func(acc string) bool {
+			_, ok := positions[acc]
+			return !ok
+		})
+	}
+
+	type accountCalendars struct {
+		account   string
+		calendars []Calendar
+		next      string
+	}
 
-		list, err := srv.CalendarList.List().Do()
+	results, accountErrors, err := forEachAccount(ctx, accounts, func(ctx context.Context, acc string) (accountCalendars, error) {
+		pos := positions[acc]
+		list, next, err := provider.ListCalendars(ctx, acc, CalendarQuery{
+			MaxResults:    pos.Skip + maxResults,
+			MinAccessRole: input.MinAccessRole,
+			ShowHidden:    input.IncludeHidden,
+			ShowDeleted:   input.IncludeDeleted,
+			SelectedOnly:  input.SelectedOnly,
+			PageToken:     pos.PageToken,
+		})
 		if err != nil {
-			return nil, fmt.Errorf("failed to list calendars for account '%s': %w", acc, err)
+			return accountCalendars{}, err
 		}
+		return accountCalendars{account: acc, calendars: list[min(pos.Skip, len(list)):], next: next}, nil
+	})
+	if err != nil {
+		return ListCalendarsOutput{}, err
+	}
 
-		for _, item := range list.Items {
-			calendars = append(calendars, Calendar{
-				ID:          item.Id,
-				Summary:     item.Summary,
-				Description: item.Description,
-				Primary:     item.Primary,
-				Account:     acc,
-			})
+	// max_results counts the calendars of all accounts, in account order. An
+	// account whose calendars did not all fit continues from the same page,
+	// past the calendars returned.
+	output := ListCalendarsOutput{Errors: accountErrors}
+	left := make(map[string]listPosition)
+	for _, res := range results {
+		pos := positions[res.account]
+		n := min(len(res.calendars), maxResults-len(output.Calendars))
+		output.Calendars = append(output.Calendars, res.calendars[:n]...)
+		switch {
+		case n < len(res.calendars):
+			left[res.account] = listPosition{PageToken: pos.PageToken, Skip: pos.Skip + n}
+		case res.next != "":
+			left[res.account] = listPosition{PageToken: res.next}
 		}
 	}
+	if len(left) > 0 {
+		output.Truncated = true
+		output.NextCursor = encodeCursor(pageCursor{Kind: "calendars", Query: queryHash(query), Lists: left}, now)
+	}
+	return output, nil
+}
+
+// GetEvents returns events matching the specified criteria.
+// Accounts that fail are reported in the output's Errors; an error is only
+// returned if no account could be queried.
+func GetEvents(ctx context.Context, provider CalendarProvider, input ListEventsInput) (ListEventsOutput, error) {
+	accounts, err := getTargetAccounts(provider, input.Account)
+	if err != nil {
+		return ListEventsOutput{}, err
+	}
+
+	zones, err := resolveUserZones(ctx, provider, accounts, input.TimeZone)
+	if err != nil {
+		return ListEventsOutput{}, err
+	}
 
-	return calendars, nil
+	return listEvents(ctx, provider, input, accounts, zones, maxListResults)
 }
 
-// GetEvents returns events matching the specified criteria
-func GetEvents(ctx context.Context, input ListEventsInput) ([]Event, error) {
-	accounts, err := getTargetAccounts(input.Account)
+// listEvents lists the events of the accounts in the zones the tool call
+// already resolved, ignoring input.Account and input.TimeZone, and returns at
+// most limit events whatever input.MaxResults asks for
+func listEvents(ctx context.Context, provider CalendarProvider, input ListEventsInput, accounts []string, zones userZones, limit int) (ListEventsOutput, error) {
+	// Default: 7 days, counted in the user's time zone
+	timeMin, timeMax, err := resolveTimeRange(input.TimeMin, input.TimeMax, time.Now(), zones.display, zones.firstDay, func(t time.Time) time.Time {
+		return t.AddDate(0, 0, 7)
+	})
 	if err != nil {
-		return nil, err
+		return ListEventsOutput{}, err
 	}
 
-	// Parse time range
-	timeMin := time.Now()
-	if input.TimeMin != "" {
-		t, err := time.Parse(time.RFC3339, input.TimeMin)
-		if err != nil {
-			return nil, fmt.Errorf("invalid time_min format: %w", err)
-		}
-		timeMin = t
+	if err := checkEventTypes(slices.Concat(input.EventTypes, input.ExcludeEventTypes)); err != nil {
+		return ListEventsOutput{}, err
+	}
+	// detail is a shorthand for the standard and full field presets
+	preset := "standard"
+	switch input.Detail {
+	case "", "compact":
+	case "full":
+		preset = "full"
+	default:
+		return ListEventsOutput{}, fmt.Errorf("invalid detail '%s' (expected compact or full)", input.Detail)
+	}
+	fields, err := resolveFields(input.Fields, preset)
+	if err != nil {
+		return ListEventsOutput{}, err
 	}
 
-	timeMax := timeMin.AddDate(0, 0, 7) // Default: 7 days
-	if input.TimeMax != "" {
-		t, err := time.Parse(time.RFC3339, input.TimeMax)
-		if err != nil {
-			return nil, fmt.Errorf("invalid time_max format: %w", err)
-		}
-		timeMax = t
+	// The filters applied below need their fields read, even when not returned
+	read := maps.Clone(fields)
+	if input.ExcludeDeclined || input.AcceptedOnly {
+		read["response_status"] = true
+	}
+	if len(input.ExcludeEventTypes) > 0 {
+		read["event_type"] = true
 	}
 
 	maxResults := input.MaxResults
 	if maxResults <= 0 {
 		maxResults = 50
 	}
-	if maxResults > 250 {
-		maxResults = 250
+	if maxResults > limit {
+		maxResults = limit
 	}
 
-	var events []Event
-	for _, acc := range accounts {
-		srv, err := GetCalendarService(ctx, acc)
+	// A cursor continues the listing where the previous call stopped, within
+	// the time range that call resolved
+	now := time.Now()
+	query := input
+	query.Cursor, query.MaxResults = "", 0
+	var after *eventPosition
+	var done []accountCalendar
+	if input.Cursor != "" {
+		cursor, err := decodeCursor(input.Cursor, "events", queryHash(query), now)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get service for account '%s': %w", acc, err)
+			return ListEventsOutput{}, err
 		}
+		timeMin, timeMax = cursor.TimeMin.In(zones.display), cursor.TimeMax.In(zones.display)
+		after, done = cursor.After, cursor.Done
+	}
 
-		calendarID := input.CalendarID
-		if calendarID == "" {
-			calendarID = "primary"
-		}
+	type accountEvents struct {
+		events    []Event
+		truncated bool
+		errors    []AccountError
+		calendars int
+		// last holds the last event read from each calendar with more
+		// events, and complete the calendars that were read to the end
+		last     []Event
+		complete []accountCalendar
+	}
 
-		call := srv.Events.List(calendarID).
-			TimeMin(timeMin.Format(time.RFC3339)).
-			TimeMax(timeMax.Format(time.RFC3339)).
-			MaxResults(int64(maxResults)).
-			SingleEvents(true).
-			OrderBy("startTime")
+	resolver := newCalendarResolver(provider)
+	results, accountErrors, err := forEachAccount(ctx, accounts, func(ctx context.Context, acc string) (accountEvents, error) {
+		calendarIDs, err := eventCalendarIDs(ctx, resolver, acc, input)
+		if err != nil {
+			return accountEvents{}, err
+		}
 
-		if input.Query != "" {
-			call = call.Q(input.Query)
+		// A failing calendar is reported on its own unless the whole account failed
+		res := accountEvents{calendars: len(calendarIDs)}
+		var errs []error
+		for _, calID := range calendarIDs {
+			cal := accountCalendar{Account: acc, CalendarID: calID}
+			if slices.Contains(done, cal) {
+				res.complete = append(res.complete, cal)
+				continue
+			}
+			list, more, err := listEventsAfter(ctx, provider, acc, zones, after, EventQuery{
+				CalendarID:    calID,
+				TimeMin:       timeMin,
+				TimeMax:       timeMax,
+				MaxResults:    maxResults,
+				Query:         input.Query,
+				SeriesMasters: input.SeriesMasters,
+				EventTypes:    input.EventTypes,
+				Fields:        apiFields(read),
+			})
+			if err != nil {
+				errs = append(errs, err)
+				res.errors = append(res.errors, AccountError{Account: acc, CalendarID: calID, Error: err.Error()})
+				continue
+			}
+			res.events = append(res.events, list...)
+			res.truncated = res.truncated || more
+			if !more {
+				res.complete = append(res.complete, cal)
+			} else if len(list) > 0 {
+				res.last = append(res.last, list[len(list)-1])
+			}
 		}
+		if len(errs) > 0 && len(errs) == len(calendarIDs) {
+			return accountEvents{}, errors.Join(errs...)
+		}
+		// The names the user gave calendars are only in the calendar list;
+		// without it events keep the calendar's own title
+		if len(res.events) > 0 {
+			resolver.calendars(ctx, acc)
+		}
+		return res, nil
+	})
+	if err != nil {
+		return ListEventsOutput{}, err
+	}
+	if refs := calendarRefs(input.CalendarID, input.CalendarIDs); !input.AllCalendars && len(refs) > 0 && len(accountErrors) == 0 &&
+		!slices.ContainsFunc(results, func(res accountEvents) bool { return res.calendars > 0 }) {
+		return ListEventsOutput{}, fmt.Errorf("no calendar matching '%s' in any account", strings.Join(refs, "', '"))
+	}
 
-		result, err := call.Do()
-		if err != nil {
-			return nil, fmt.Errorf("failed to list events for account '%s': %w", acc, err)
+	// Merge every account into one chronological list, then apply the cap
+	// to the merged list rather than to each account
+	output := ListEventsOutput{
+		TimeMin:  timeMin,
+		TimeMax:  timeMax,
+		TimeZone: zones.display.String(),
+		Errors:   accountErrors,
+	}
+	var last []Event
+	var complete []accountCalendar
+	for _, res := range results {
+		output.Events = append(output.Events, res.events...)
+		output.Truncated = output.Truncated || res.truncated
+		output.Errors = append(output.Errors, res.errors...)
+		last = append(last, res.last...)
+		complete = append(complete, res.complete...)
+	}
+	for i := range output.Events {
+		ev := &output.Events[i]
+		zones.localizeEvent(ev)
+		// Prefer the name the user gave the calendar when it is known
+		if name, ok := resolver.cachedName(ev.Account, ev.CalendarID); ok {
+			ev.CalendarName = name
 		}
+	}
 
-		for _, item := range result.Items {
-			events = append(events, parseEvent(item, acc, calendarID))
+	// A calendar with more events may have unread events starting as early as
+	// the last one read from it, so events starting at or after the earliest
+	// of those starts are held back: the API orders events by start time only
+	var cut time.Time
+	held := false
+	for i := range last {
+		if input.SeriesMasters {
+			break
+		}
+		zones.localizeEvent(&last[i])
+		if !held || last[i].Start.Before(cut) {
+			cut, held = last[i].Start, true
 		}
 	}
+	output.Events = slices.DeleteFunc(output.Events, func(ev Event) bool {
+		return after != nil && compareEvents(ev, after.event()) <= 0
+	})
+	fetched := slices.Clone(output.Events)
+	output.Events = slices.DeleteFunc(output.Events, func(ev Event) bool {
+		return held && !ev.Start.Before(cut)
+	})
+
+	// The next page starts after the last event returned, or after the last
+	// event that could be, when the filters left out the ones before it
+	var position *Event
+	for _, ev := range output.Events {
+		if position == nil || compareEvents(ev, *position) > 0 {
+			position = &ev
+		}
+	}
+
+	// Events the user is not invited to, such as their own, count as accepted
+	output.Events = slices.DeleteFunc(output.Events, func(ev Event) bool {
+		return (input.ExcludeDeclined && ev.ResponseStatus == "declined") ||
+			(input.AcceptedOnly && ev.ResponseStatus != "" && ev.ResponseStatus != "accepted") ||
+			slices.Contains(input.ExcludeEventTypes, cmp.Or(ev.EventType, "default"))
+	})
+	sortEvents(output.Events)
+
+	if len(output.Events) > maxResults {
+		output.Events = output.Events[:maxResults]
+		output.Truncated = true
+		position = &output.Events[maxResults-1]
+	}
+
+	// Calendars read to the end are done once none of their events are left.
+	// Series masters are not ordered, so their listing cannot be continued.
+	if output.Truncated && position != nil && !input.SeriesMasters && (after == nil || compareEvents(*position, after.event()) > 0) {
+		done = slices.DeleteFunc(complete, func(cal accountCalendar) bool {
+			return slices.ContainsFunc(fetched, func(ev Event) bool {
+				return ev.Account == cal.Account && ev.CalendarID == cal.CalendarID && compareEvents(ev, *position) > 0
+			})
+		})
+		output.NextCursor = encodeCursor(pageCursor{
+			Kind:    "events",
+			Query:   queryHash(query),
+			TimeMin: timeMin,
+			TimeMax: timeMax,
+			After:   positionOf(*position),
+			Done:    done,
+		}, now)
+	}
+	for i := range output.Events {
+		projectEvent(&output.Events[i], fields)
+	}
+	resolveEventColors(ctx, provider, output.Events)
+
+	return output, nil
+}
 
-	return events, nil
+// listAllEvents reads every event of a listing in one go, up to maxEvents
+// events rather than the page size of the listing tools
+func listAllEvents(ctx context.Context, provider CalendarProvider, input ListEventsInput, accounts []string, zones userZones, maxEvents int) (ListEventsOutput, error) {
+	input.MaxResults = maxEvents
+	return listEvents(ctx, provider, input, accounts, zones, maxEvents)
+}
+
+// listEventsAfter lists the events of a calendar that come after a cursor
+// position, if any. As the API selects events by their end time, the events
+// overlapping the position are read again. When the calendar has more events,
+// the listing is widened until an event past the position starts before the
+// last one read, as later events may share that last start time.
+func listEventsAfter(ctx context.Context, provider CalendarProvider, account string, zones userZones, after *eventPosition, query EventQuery) ([]Event, bool, error) {
+	// One event more than needed tells whether the last one needed shares its
+	// start time with the next
+	query.MaxResults++
+	if after != nil {
+		if after.Start.After(query.TimeMin) {
+			query.TimeMin = after.Start
+		}
+		// The event at the position is usually read again
+		query.MaxResults++
+	}
+	for {
+		list, more, err := provider.ListEvents(ctx, account, query)
+		if err != nil || !more || len(list) == 0 || query.MaxResults >= maxCursorRead {
+			return list, more, err
+		}
+		local := slices.Clone(list)
+		for i := range local {
+			zones.localizeEvent(&local[i])
+		}
+		first := slices.IndexFunc(local, func(ev Event) bool {
+			return after == nil || compareEvents(ev, after.event()) > 0
+		})
+		if first >= 0 && local[first].Start.Before(local[len(local)-1].Start) {
+			return list, more, nil
+		}
+		query.MaxResults = min(query.MaxResults*2, maxCursorRead)
+	}
 }
 
-// GetEvent returns details for a specific event
-func GetEvent(ctx context.Context, accountName, calendarID, eventID string) (*Event, error) {
-	srv, err := GetCalendarService(ctx, accountName)
+// GetEvent returns details for a specific event, along with the fields it
+// was projected on. The calendar may be given by ID, alias or name.
+func GetEvent(ctx context.Context, provider CalendarProvider, input GetEventInput) (*Event, map[string]bool, error) {
+	zones, err := resolveUserZones(ctx, provider, []string{input.Account}, input.TimeZone)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	calendarID, err := newCalendarResolver(provider).resolve(ctx, input.Account, input.CalendarID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	item, err := srv.Events.Get(calendarID, eventID).Do()
+	fields, err := resolveFields(input.Fields, "full")
 	if err != nil {
-		return nil, fmt.Errorf("failed to get event: %w", err)
+		return nil, nil, err
 	}
 
-	event := parseEvent(item, accountName, calendarID)
-	return &event, nil
+	event, err := provider.GetEvent(ctx, input.Account, calendarID, input.EventID, apiFields(fields))
+	if err != nil {
+		return nil, nil, err
+	}
+	zones.localizeEvent(event)
+	projectEvent(event, fields)
+	events := []Event{*event}
+	resolveEventColors(ctx, provider, events)
+	return &events[0], fields, nil
 }
 
-// CheckAvailability returns busy periods for the specified calendars
-func CheckAvailability(ctx context.Context, input CheckAvailabilityInput) ([]BusyPeriod, error) {
-	accounts, err := getTargetAccounts(input.Account)
+// GetEventInstances returns the series of a recurring event and its instances.
+// The event may be the series master or any of its instances.
+func GetEventInstances(ctx context.Context, provider CalendarProvider, input ListEventInstancesInput) (ListEventInstancesOutput, error) {
+	zones, err := resolveUserZones(ctx, provider, []string{input.Account}, input.TimeZone)
 	if err != nil {
-		return nil, err
+		return ListEventInstancesOutput{}, err
 	}
 
-	timeMin, err := time.Parse(time.RFC3339, input.TimeMin)
+	resolver := newCalendarResolver(provider)
+	calendarID, err := resolver.resolve(ctx, input.Account, input.CalendarID)
 	if err != nil {
-		return nil, fmt.Errorf("invalid time_min format: %w", err)
+		return ListEventInstancesOutput{}, err
 	}
 
-	timeMax, err := time.Parse(time.RFC3339, input.TimeMax)
+	// Default: every instance from now on
+	timeMin, timeMax, err := resolveTimeRange(input.TimeMin, input.TimeMax, time.Now(), zones.display, zones.firstDay, func(time.Time) time.Time {
+		return time.Time{}
+	})
 	if err != nil {
-		return nil, fmt.Errorf("invalid time_max format: %w", err)
+		return ListEventInstancesOutput{}, err
+	}
+
+	maxResults := input.MaxResults
+	if maxResults <= 0 {
+		maxResults = 50
+	}
+	if maxResults > 250 {
+		maxResults = 250
 	}
 
-	var busyPeriods []BusyPeriod
-	for _, acc := range accounts {
-		srv, err := GetCalendarService(ctx, acc)
+	// A cursor continues the listing at the page where the previous call
+	// stopped, within the time range that call resolved
+	now := time.Now()
+	query := input
+	query.Cursor, query.MaxResults = "", 0
+	pageToken := ""
+	if input.Cursor != "" {
+		cursor, err := decodeCursor(input.Cursor, "instances", queryHash(query), now)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get service for account '%s': %w", acc, err)
+			return ListEventInstancesOutput{}, err
 		}
+		timeMin, timeMax = cursor.TimeMin.In(zones.display), cursor.TimeMax.In(zones.display)
+		pageToken = cursor.PageToken
+	}
 
-		calendars := input.Calendars
-		if len(calendars) == 0 {
-			calendars = []string{"primary"}
+	series, err := provider.GetEvent(ctx, input.Account, calendarID, input.EventID, nil)
+	if err != nil {
+		return ListEventInstancesOutput{}, err
+	}
+	if series.RecurringEventID != "" {
+		series, err = provider.GetEvent(ctx, input.Account, calendarID, series.RecurringEventID, nil)
+		if err != nil {
+			return ListEventInstancesOutput{}, err
 		}
+	}
+	if len(series.Recurrence) == 0 {
+		return ListEventInstancesOutput{}, fmt.Errorf("event '%s' is not a recurring event", input.EventID)
+	}
+
+	instances, next, err := provider.ListInstances(ctx, input.Account, InstancesQuery{
+		CalendarID: calendarID,
+		EventID:    series.ID,
+		TimeMin:    timeMin,
+		TimeMax:    timeMax,
+		MaxResults: maxResults,
+		PageToken:  pageToken,
+	})
+	if err != nil {
+		return ListEventInstancesOutput{}, err
+	}
 
-		var items []*calendar.FreeBusyRequestItem
-		for _, calID := range calendars {
-			items = append(items, &calendar.FreeBusyRequestItem{Id: calID})
+	// The details of the series are those of its instances
+	zones.localizeEvent(series)
+	resolver.calendars(ctx, input.Account)
+	name, named := resolver.cachedName(input.Account, calendarID)
+	for i := range instances {
+		zones.localizeEvent(&instances[i])
+		instances[i].Details = nil
+		if named {
+			instances[i].CalendarName = name
 		}
+	}
+
+	output := ListEventInstancesOutput{
+		Series:    *series,
+		Instances: instances,
+		TimeMin:   timeMin,
+		TimeMax:   timeMax,
+		TimeZone:  zones.display.String(),
+		Truncated: next != "",
+	}
+	if next != "" {
+		output.NextCursor = encodeCursor(pageCursor{
+			Kind:      "instances",
+			Query:     queryHash(query),
+			TimeMin:   timeMin,
+			TimeMax:   timeMax,
+			PageToken: next,
+		}, now)
+	}
+	return output, nil
+}
 
-		req := &calendar.FreeBusyRequest{
-			TimeMin: timeMin.Format(time.RFC3339),
-			TimeMax: timeMax.Format(time.RFC3339),
-			Items:   items,
+// CheckAvailability returns busy periods for the specified calendars.
+// Accounts that fail are reported in the output's Errors; an error is only
+// returned if no account could be queried.
+func CheckAvailability(ctx context.Context, provider CalendarProvider, input CheckAvailabilityInput) (CheckAvailabilityOutput, error) {
+	accounts, err := getTargetAccounts(provider, input.Account)
+	if err != nil {
+		return CheckAvailabilityOutput{}, err
+	}
+
+	zones, err := resolveUserZones(ctx, provider, accounts, input.TimeZone)
+	if err != nil {
+		return CheckAvailabilityOutput{}, err
+	}
+
+	timeMin, timeMax, err := resolveTimeRange(input.TimeMin, input.TimeMax, time.Now(), zones.display, zones.firstDay, endOfDay)
+	if err != nil {
+		return CheckAvailabilityOutput{}, err
+	}
+
+	output, err := queryBusyPeriods(ctx, provider, accounts, input.Calendars, input.Account == "", timeMin, timeMax)
+	if err != nil {
+		return CheckAvailabilityOutput{}, err
+	}
+	for i := range output.BusyPeriods {
+		output.BusyPeriods[i].Start = output.BusyPeriods[i].Start.In(zones.display)
+		output.BusyPeriods[i].End = output.BusyPeriods[i].End.In(zones.display)
+	}
+	if input.Merge {
+		output.BusyBlocks = mergeBusyBlocks(output.BusyPeriods)
+	}
+	output.TimeMin, output.TimeMax = timeMin, timeMax
+	output.TimeZone = zones.display.String()
+	return output, nil
+}
+
+// queryBusyPeriods collects the busy periods of the given calendars across
+// accounts, reporting calendars without free/busy information in the output's
+// Errors. Calendar names that an account does not have are skipped when
+// skipMissing is set, as when no account was explicitly requested.
+func queryBusyPeriods(ctx context.Context, provider CalendarProvider, accounts, calendars []string, skipMissing bool, timeMin, timeMax time.Time) (CheckAvailabilityOutput, error) {
+	type accountBusy struct {
+		periods   []BusyPeriod
+		errors    []AccountError
+		calendars int
+	}
+
+	resolver := newCalendarResolver(provider)
+	results, accountErrors, err := forEachAccount(ctx, accounts, func(ctx context.Context, acc string) (accountBusy, error) {
+		calendarIDs, err := resolveCalendarRefs(ctx, resolver, acc, calendars, skipMissing)
+		if err != nil || len(calendarIDs) == 0 {
+			return accountBusy{}, err
 		}
+		periods, calendarErrors, err := provider.QueryFreeBusy(ctx, acc, calendarIDs, timeMin, timeMax)
+		return accountBusy{periods: periods, errors: calendarErrors, calendars: len(calendarIDs)}, err
+	})
+	if err != nil {
+		return CheckAvailabilityOutput{}, err
+	}
+	if refs := calendarRefs("", calendars); len(refs) > 0 && len(accountErrors) == 0 &&
+		!slices.ContainsFunc(results, func(res accountBusy) bool { return res.calendars > 0 }) {
+		return CheckAvailabilityOutput{}, fmt.Errorf("no calendar matching '%s' in any account", strings.Join(refs, "', '"))
+	}
 
-		result, err := srv.Freebusy.Query(req).Do()
-		if err != nil {
-			return nil, fmt.Errorf("failed to query free/busy for account '%s': %w", acc, err)
-		}
-
-		for _, cal := range result.Calendars {
-			for _, busy := range cal.Busy {
-				start, _ := time.Parse(time.RFC3339, busy.Start)
-				end, _ := time.Parse(time.RFC3339, busy.End)
-				busyPeriods = append(busyPeriods, BusyPeriod{
-					Start:   start,
-					End:     end,
-					Account: acc,
-				})
+	output := CheckAvailabilityOutput{Errors: accountErrors}
+	for _, res := range results {
+		output.BusyPeriods = append(output.BusyPeriods, res.periods...)
+		output.Errors = append(output.Errors, res.errors...)
+	}
+
+	return output, nil
+}
+
+// mergeBusyBlocks merges busy periods into a sorted timeline of non-overlapping
+// blocks, keeping track of the distinct calendars behind each block, so that
+// a meeting present on several calendars or accounts counts once
+func mergeBusyBlocks(periods []BusyPeriod) []BusyBlock {
+	sorted := slices.Clone(periods)
+	slices.SortFunc(sorted, func(a, b BusyPeriod) int {
+		return cmp.Or(a.Start.Compare(b.Start), a.End.Compare(b.End))
+	})
+
+	var blocks []BusyBlock
+	for _, bp := range sorted {
+		source := BusySource{Account: bp.Account, CalendarID: bp.CalendarID}
+		if n := len(blocks); n > 0 && !bp.Start.After(blocks[n-1].End) {
+			last := &blocks[n-1]
+			if bp.End.After(last.End) {
+				last.End = bp.End
 			}
+			if !slices.Contains(last.Sources, source) {
+				last.Sources = append(last.Sources, source)
+			}
+			continue
 		}
+		blocks = append(blocks, BusyBlock{Start: bp.Start, End: bp.End, Sources: []BusySource{source}})
 	}
-
-	return busyPeriods, nil
+	return blocks
 }
 
 // Helper functions
 
-func getTargetAccounts(accountName string) ([]string, error) {
+func getTargetAccounts(provider CalendarProvider, accountName string) ([]string, error) {
 	if accountName != "" {
 		return []string{accountName}, nil
 	}
-	return ListConfiguredAccounts()
+	return provider.Accounts()
+}
+
+// eventCalendarIDs returns the calendars of an account that list_events should
+// query: the selected calendars in all-calendars mode, otherwise the requested
+// calendars, defaulting to the primary calendar
+func eventCalendarIDs(ctx context.Context, resolver *calendarResolver, account string, input ListEventsInput) ([]string, error) {
+	if input.AllCalendars {
+		calendars, err := resolver.calendars(ctx, account)
+		if err != nil {
+			return nil, err
+		}
+
+		var ids []string
+		for _, cal := range calendars {
+			if cal.Selected || cal.Primary {
+				ids = append(ids, cal.ID)
+			}
+		}
+		return ids, nil
+	}
+
+	refs := append([]string{input.CalendarID}, input.CalendarIDs...)
+	return resolveCalendarRefs(ctx, resolver, account, refs, input.Account == "")
 }
 
-func parseEvent(item *calendar.Event, account, calendarID string) Event {
-	var start, end time.Time
-	allDay := false
+// resolveCalendarRefs resolves calendar references (IDs, aliases or names) to
+// the distinct calendar IDs of an account, defaulting to the primary calendar
+// when no reference is given. With skipMissing, references that match no
+// calendar of the account are dropped instead of failing, so that a calendar
+// name can be used while querying every account.
+func resolveCalendarRefs(ctx context.Context, resolver *calendarResolver, account string, refs []string, skipMissing bool) ([]string, error) {
+	refs = calendarRefs("", refs)
+	if len(refs) == 0 {
+		return []string{"primary"}, nil
+	}
+
+	var ids []string
+	for _, ref := range refs {
+		id, err := resolver.resolve(ctx, account, ref)
+		if err != nil {
+			if skipMissing && isCalendarNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+
+	// "primary" and the primary calendar's own ID are the same calendar
+	if len(ids) > 1 && slices.Contains(ids, "primary") {
+		primary, err := resolver.primaryID(ctx, account)
+		if err != nil {
+			return nil, err
+		}
+		if i, j := slices.Index(ids, "primary"), slices.Index(ids, primary); j >= 0 {
+			ids = slices.Delete(ids, max(i, j), max(i, j)+1)
+		}
+	}
+	return ids, nil
+}
 
-	if item.Start != nil {
-		if item.Start.DateTime != "" {
-			start, _ = time.Parse(time.RFC3339, item.Start.DateTime)
-		} else if item.Start.Date != "" {
-			start, _ = time.Parse("2006-01-02", item.Start.Date)
-			allDay = true
+// calendarRefs returns the non-empty, distinct calendar references among first and rest
+func calendarRefs(first string, rest []string) []string {
+	var refs []string
+	for _, ref := range append([]string{first}, rest...) {
+		if ref = strings.TrimSpace(ref); ref != "" && !slices.Contains(refs, ref) {
+			refs = append(refs, ref)
 		}
 	}
+	return refs
+}
 
-	if item.End != nil {
-		if item.End.DateTime != "" {
-			end, _ = time.Parse(time.RFC3339, item.End.DateTime)
-		} else if item.End.Date != "" {
-			end, _ = time.Parse("2006-01-02", item.End.Date)
+// sortEvents orders events chronologically. Ties are broken by putting all-day
+// events first, then by end time, account, calendar and event ID, so that the
+// order does not depend on which account answered first.
+func sortEvents(events []Event) {
+	slices.SortStableFunc(events, compareEvents)
+}
+
+func compareEvents(a, b Event) int {
+	if c := a.Start.Compare(b.Start); c != 0 {
+		return c
+	}
+	if a.AllDay != b.AllDay {
+		if a.AllDay {
+			return -1
 		}
+		return 1
 	}
+	if c := a.End.Compare(b.End); c != 0 {
+		return c
+	}
+	return cmp.Or(
+		strings.Compare(a.Account, b.Account),
+		strings.Compare(a.CalendarID, b.CalendarID),
+		strings.Compare(a.ID, b.ID),
+	)
+}
 
-	var attendees []string
-	for _, att := range item.Attendees {
-		attendees = append(attendees, att.Email)
+// forEachAccount calls fn for every account on a bounded pool of workers,
+// giving each call its own timeout. It returns the results of the accounts
+// that succeeded, in account order, and an AccountError for each that failed.
+// If every account failed, the joined errors are returned instead.
+func forEachAccount[T any](ctx context.Context, accounts []string, fn func(ctx context.Context, account string) (T, error)) ([]T, []AccountError, error) {
+	results := make([]T, len(accounts))
+	errs := make([]error, len(accounts))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(maxConcurrentAccounts, len(accounts)) {
+		wg.Go(func() {
+			for i := range jobs {
+				accCtx, cancel := context.WithTimeout(ctx, accountTimeout)
+				results[i], errs[i] = fn(accCtx, accounts[i])
+				cancel()
+			}
+		})
+	}
+	for i := range accounts {
+		jobs <- i
 	}
+	close(jobs)
+	wg.Wait()
 
-	var organizer string
-	if item.Organizer != nil {
-		organizer = item.Organizer.Email
+	var succeeded []T
+	var accountErrors []AccountError
+	for i, err := range errs {
+		if err != nil {
+			accountErrors = append(accountErrors, AccountError{Account: accounts[i], Error: err.Error()})
+			continue
+		}
+		succeeded = append(succeeded, results[i])
 	}
 
-	return Event{
-		ID:          item.Id,
-		Summary:     item.Summary,
-		Description: item.Description,
-		Location:    item.Location,
-		Start:       start,
-		End:         end,
-		AllDay:      allDay,
-		Attendees:   attendees,
-		Organizer:   organizer,
-		Status:      item.Status,
-		HtmlLink:    item.HtmlLink,
-		Account:     account,
-		CalendarID:  calendarID,
+	if len(accounts) > 0 && len(accountErrors) == len(accounts) {
+		return nil, nil, errors.Join(errs...)
 	}
+
+	return succeeded, accountErrors, nil
 }
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// TestForEachAccountBounded verifies results keep account order and concurrency is bounded
+func TestForEachAccountBounded(t *testing.T) {
+	var accounts []string
+	for i := range 10 {
+		accounts = append(accounts, fmt.Sprintf("acc%d", i))
+	}
+
+	var running, peak atomic.Int32
+	results, accountErrors, err := forEachAccount(context.Background(), accounts, func(ctx context.Context, account string) (string, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		return account, nil
+	})
+	if err != nil || len(accountErrors) != 0 {
+		t.Fatalf("Unexpected failure: %v %+v", err, accountErrors)
+	}
+
+	for i, res := range results {
+		if res != accounts[i] {
+			t.Errorf("Expected %s at position %d, got %s", accounts[i], i, res)
+		}
+	}
+	if p := peak.Load(); p > maxConcurrentAccounts || p < 2 {
+		t.Errorf("Expected concurrency between 2 and %d, got %d", maxConcurrentAccounts, p)
+	}
+}
+
+// TestForEachAccountFailures verifies partial and total failures
+func TestForEachAccountFailures(t *testing.T) {
+	fn := func(ctx context.Context, account string) (string, error) {
+		if account == "work" || account == "old" {
+			return "", fmt.Errorf("%s: token expired", account)
+		}
+		return account, nil
+	}
+
+	results, accountErrors, err := forEachAccount(context.Background(), []string{"personal", "work"}, fn)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(results) != 1 || results[0] != "personal" {
+		t.Errorf("Expected personal result only, got %v", results)
+	}
+	if len(accountErrors) != 1 || accountErrors[0].Account != "work" {
+		t.Errorf("Expected work failure, got %+v", accountErrors)
+	}
+
+	_, _, err = forEachAccount(context.Background(), []string{"work", "old"}, fn)
+	if err == nil {
+		t.Fatal("Expected an error when every account fails")
+	}
+	for _, want := range []string{"work: token expired", "old: token expired"} {
+		if !contains(err.Error(), want) {
+			t.Errorf("Expected %q in %q", want, err.Error())
+		}
+	}
+}
+
+// TestForEachAccountTimeout verifies each account call carries a deadline
+func TestForEachAccountTimeout(t *testing.T) {
+	_, _, err := forEachAccount(context.Background(), []string{"slow"}, func(ctx context.Context, account string) (struct{}, error) {
+		deadline, ok := ctx.Deadline()
+		if !ok || time.Until(deadline) > accountTimeout {
+			return struct{}{}, errors.New("missing per-account deadline")
+		}
+		return struct{}{}, nil
+	})
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+// TestSortEvents verifies the chronological order and its tie-breakers
+func TestSortEvents(t *testing.T) {
+	at := func(hour int) time.Time { return time.Date(2026, 2, 2, hour, 0, 0, 0, time.UTC) }
+
+	events := []Event{
+		{ID: "late", Start: at(15), End: at(16), Account: "personal"},
+		{ID: "b", Start: at(9), End: at(10), Account: "work", CalendarID: "primary"},
+		{ID: "a", Start: at(9), End: at(10), Account: "work", CalendarID: "primary"},
+		{ID: "long", Start: at(9), End: at(12), Account: "personal"},
+		{ID: "personal", Start: at(9), End: at(10), Account: "personal"},
+		{ID: "allday", Start: at(0), End: at(24), AllDay: true, Account: "work"},
+		{ID: "midnight", Start: at(0), End: at(1), Account: "personal"},
+	}
+	sortEvents(events)
+
+	var ids []string
+	for _, ev := range events {
+		ids = append(ids, ev.ID)
+	}
+	want := []string{"allday", "midnight", "personal", "a", "b", "long", "late"}
+	if !slices.Equal(ids, want) {
+		t.Errorf("Expected %v, got %v", want, ids)
+	}
+}
+
+// TestMergeBusyBlocks verifies overlapping periods merge and keep their distinct sources
+func TestMergeBusyBlocks(t *testing.T) {
+	at := func(hour, minute int) time.Time { return time.Date(2026, 2, 2, hour, minute, 0, 0, time.UTC) }
+
+	blocks := mergeBusyBlocks([]BusyPeriod{
+		{Start: at(14, 0), End: at(15, 0), Account: "work", CalendarID: "team"},
+		{Start: at(9, 0), End: at(10, 0), Account: "work", CalendarID: "primary"},
+		{Start: at(9, 0), End: at(10, 0), Account: "personal", CalendarID: "primary"},
+		{Start: at(9, 30), End: at(10, 30), Account: "work", CalendarID: "primary"},
+	})
+
+	if len(blocks) != 2 {
+		t.Fatalf("Expected 2 blocks, got %+v", blocks)
+	}
+	if !blocks[0].Start.Equal(at(9, 0)) || !blocks[0].End.Equal(at(10, 30)) {
+		t.Errorf("Expected the first block to span 09:00-10:30, got %+v", blocks[0])
+	}
+	want := []BusySource{{Account: "work", CalendarID: "primary"}, {Account: "personal", CalendarID: "primary"}}
+	if !slices.Equal(blocks[0].Sources, want) {
+		t.Errorf("Expected sources %+v, got %+v", want, blocks[0].Sources)
+	}
+	if len(blocks[1].Sources) != 1 || blocks[1].Sources[0].CalendarID != "team" {
+		t.Errorf("Expected the team calendar alone, got %+v", blocks[1].Sources)
+	}
+}
+
+// TestEventCursorPaging verifies that following list_events cursors returns
+// the events of a single listing in the same order, when events of several
+// calendars and accounts share their start time and pages end among them
+func TestEventCursorPaging(t *testing.T) {
+	for seed := range uint64(48) {
+		rng := rand.New(rand.NewPCG(seed, 0))
+		accounts := make(map[string]*fakeCalendarAPI)
+		for _, acc := range []string{"a", "b"} {
+			ids := []string{acc + "@x.com", "team-" + acc + "@group.calendar.google.com"}
+			api := newFakeCalendarAPI(
+				&calendar.CalendarListEntry{Id: ids[0], Summary: ids[0], Primary: true, AccessRole: "owner", TimeZone: "UTC", Selected: true},
+				&calendar.CalendarListEntry{Id: ids[1], Summary: "Team " + acc, AccessRole: "reader", TimeZone: "UTC", Selected: true},
+			)
+			api.timeZone = "UTC"
+			api.pageSize = 1 + rng.IntN(4)
+			for _, id := range ids {
+				for i := range 2 + rng.IntN(6) {
+					ev := &calendar.Event{Id: fmt.Sprintf("%s-%d", id, i), Summary: "Event", Status: "confirmed"}
+					day := 2 + rng.IntN(2)
+					if rng.IntN(4) == 0 {
+						ev.Start = &calendar.EventDateTime{Date: fmt.Sprintf("2026-02-%02d", day)}
+						ev.End = &calendar.EventDateTime{Date: fmt.Sprintf("2026-02-%02d", day+1)}
+					} else {
+						start := time.Date(2026, 2, day, 9+rng.IntN(2), 0, 0, 0, time.UTC)
+						ev.Start = &calendar.EventDateTime{DateTime: start.Format(time.RFC3339)}
+						ev.End = &calendar.EventDateTime{DateTime: start.Add(time.Duration(1+rng.IntN(3)) * 30 * time.Minute).Format(time.RFC3339)}
+					}
+					if rng.IntN(5) == 0 {
+						ev.Attendees = []*calendar.EventAttendee{{Email: acc + "@x.com", Self: true, ResponseStatus: "declined"}}
+					}
+					api.addEvents(id, ev)
+				}
+			}
+			accounts[acc] = api
+		}
+		provider := newFakeGoogleProvider(t, accounts)
+
+		input := ListEventsInput{
+			AllCalendars:    true,
+			TimeMin:         "2026-02-01T00:00:00Z",
+			TimeMax:         "2026-02-08T00:00:00Z",
+			ExcludeDeclined: seed%2 == 1,
+			MaxResults:      250,
+		}
+		all, err := GetEvents(context.Background(), provider, input)
+		if err != nil {
+			t.Fatalf("seed %d: GetEvents() error: %v", seed, err)
+		}
+		var want []string
+		for _, ev := range all.Events {
+			want = append(want, ev.CalendarID+"/"+ev.ID)
+		}
+
+		for maxResults := 1; maxResults <= 4; maxResults++ {
+			input.MaxResults, input.Cursor = maxResults, ""
+			var got []string
+			for page := 0; ; page++ {
+				if page > len(want) {
+					t.Fatalf("seed %d: cursors did not end after %d pages of %d", seed, page, maxResults)
+				}
+				out, err := GetEvents(context.Background(), provider, input)
+				if err != nil {
+					t.Fatalf("seed %d: GetEvents() error: %v", seed, err)
+				}
+				for _, ev := range out.Events {
+					got = append(got, ev.CalendarID+"/"+ev.ID)
+				}
+				if out.NextCursor == "" {
+					break
+				}
+				input.Cursor = out.NextCursor
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("seed %d, pages of %d: expected %v, got %v", seed, maxResults, want, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// ColorPalette holds the colors of calendars and events, keyed by color ID
+type ColorPalette struct {
+	Calendar map[string]Color
+	Event    map[string]Color
+}
+
+// eventColorNames are the names the Google Calendar UI gives event colors
+var eventColorNames = map[string]string{
+	"1":  "Lavender",
+	"2":  "Sage",
+	"3":  "Grape",
+	"4":  "Flamingo",
+	"5":  "Banana",
+	"6":  "Tangerine",
+	"7":  "Peacock",
+	"8":  "Graphite",
+	"9":  "Blueberry",
+	"10": "Basil",
+	"11": "Tomato",
+}
+
+// calendarColorNames are the names the Google Calendar UI gives calendar colors
+var calendarColorNames = map[string]string{
+	"1":  "Cocoa",
+	"2":  "Flamingo",
+	"3":  "Tomato",
+	"4":  "Tangerine",
+	"5":  "Pumpkin",
+	"6":  "Mango",
+	"7":  "Eucalyptus",
+	"8":  "Basil",
+	"9":  "Pistachio",
+	"10": "Avocado",
+	"11": "Citron",
+	"12": "Banana",
+	"13": "Sage",
+	"14": "Peacock",
+	"15": "Cobalt",
+	"16": "Blueberry",
+	"17": "Lavender",
+	"18": "Wisteria",
+	"19": "Graphite",
+	"20": "Birch",
+	"21": "Radicchio",
+	"22": "Cherry blossom",
+	"23": "Grape",
+	"24": "Amethyst",
+}
+
+// paletteColors names the color definitions of an API palette
+func paletteColors(defs map[string]calendar.ColorDefinition, names map[string]string) map[string]Color {
+	colors := make(map[string]Color, len(defs))
+	for id, def := range defs {
+		colors[id] = Color{
+			ID:         id,
+			Name:       names[id],
+			Background: def.Background,
+			Foreground: def.Foreground,
+		}
+	}
+	return colors
+}
+
+// namedColor returns a color known by name only, or nil without a color ID
+func namedColor(id string, names map[string]string) *Color {
+	if id == "" {
+		return nil
+	}
+	return &Color{ID: id, Name: names[id]}
+}
+
+// sortedColors returns the colors of a palette by numeric ID
+func sortedColors(colors map[string]Color) []Color {
+	sorted := []Color{}
+	for _, c := range colors {
+		sorted = append(sorted, c)
+	}
+	slices.SortFunc(sorted, func(a, b Color) int {
+		x, _ := strconv.Atoi(a.ID)
+		y, _ := strconv.Atoi(b.ID)
+		return cmp.Or(cmp.Compare(x, y), cmp.Compare(a.ID, b.ID))
+	})
+	return sorted
+}
+
+// resolveEventColors fills in the hex values of the events' colors. The
+// palettes are fetched concurrently, and only for accounts with colored
+// events; when one cannot be fetched the colors keep their name only.
+func resolveEventColors(ctx context.Context, provider CalendarProvider, events []Event) {
+	var accounts []string
+	for _, ev := range events {
+		if ev.ColorID != "" && !slices.Contains(accounts, ev.Account) {
+			accounts = append(accounts, ev.Account)
+		}
+	}
+
+	type accountPalette struct {
+		account string
+		palette *ColorPalette
+	}
+	results, _, _ := forEachAccount(ctx, accounts, func(ctx context.Context, acc string) (accountPalette, error) {
+		palette, err := provider.Colors(ctx, acc)
+		return accountPalette{account: acc, palette: palette}, err
+	})
+	palettes := make(map[string]*ColorPalette, len(results))
+	for _, res := range results {
+		palettes[res.account] = res.palette
+	}
+
+	for i := range events {
+		ev := &events[i]
+		palette := palettes[ev.Account]
+		if ev.ColorID == "" || palette == nil {
+			continue
+		}
+		if color, ok := palette.Event[ev.ColorID]; ok {
+			ev.Color = &color
+		}
+	}
+}
+
+// GetColors returns the calendar and event color palettes of an account, the
+// first configured account by default
+func GetColors(ctx context.Context, provider CalendarProvider, input GetColorsInput) (GetColorsOutput, error) {
+	accounts, err := getTargetAccounts(provider, input.Account)
+	if err != nil {
+		return GetColorsOutput{}, err
+	}
+	if len(accounts) == 0 {
+		return GetColorsOutput{}, errors.New("no accounts configured")
+	}
+
+	palette, err := provider.Colors(ctx, accounts[0])
+	if err != nil {
+		return GetColorsOutput{}, err
+	}
+	return GetColorsOutput{
+		Account:  accounts[0],
+		Calendar: sortedColors(palette.Calendar),
+		Event:    sortedColors(palette.Event),
+	}, nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"cmp"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// urlPattern matches the URLs of plain text and HTML descriptions
+var urlPattern = regexp.MustCompile(`https?://[^\s<>"'()\[\]]+`)
+
+// meetingHosts maps the hosts of common meeting providers to their name;
+// subdomains match too
+var meetingHosts = map[string]string{
+	"zoom.us":             "Zoom",
+	"zoomgov.com":         "Zoom",
+	"teams.microsoft.com": "Microsoft Teams",
+	"teams.live.com":      "Microsoft Teams",
+	"meet.google.com":     "Google Meet",
+	"webex.com":           "Webex",
+	"gotomeeting.com":     "GoTo Meeting",
+	"meet.goto.com":       "GoTo Meeting",
+	"whereby.com":         "Whereby",
+	"meet.jit.si":         "Jitsi Meet",
+	"bluejeans.com":       "BlueJeans",
+	"chime.aws":           "Amazon Chime",
+}
+
+// parseConference returns how to join the event: its conference data if any,
+// else its Hangouts/Meet link, else the meeting links found in its location
+// or description
+func parseConference(item *calendar.Event) *Conference {
+	if data := item.ConferenceData; data != nil && len(data.EntryPoints) > 0 {
+		conf := &Conference{
+			ID:     data.ConferenceId,
+			Notes:  data.Notes,
+			Source: "conference_data",
+		}
+		if data.ConferenceSolution != nil {
+			conf.Solution = data.ConferenceSolution.Name
+		}
+		for _, ep := range data.EntryPoints {
+			conf.EntryPoints = append(conf.EntryPoints, EntryPoint{
+				Type:       ep.EntryPointType,
+				URI:        ep.Uri,
+				Label:      ep.Label,
+				PIN:        cmp.Or(ep.Pin, ep.AccessCode),
+				Passcode:   cmp.Or(ep.Passcode, ep.Password, ep.MeetingCode),
+				RegionCode: ep.RegionCode,
+			})
+		}
+		return conf
+	}
+
+	if item.HangoutLink != "" {
+		return &Conference{
+			Solution:    "Google Meet",
+			EntryPoints: []EntryPoint{{Type: "video", URI: item.HangoutLink}},
+			Source:      "hangout_link",
+		}
+	}
+
+	var conf *Conference
+	for _, link := range extractMeetingURLs(item.Location, item.Description) {
+		if conf == nil {
+			conf = &Conference{Solution: link.provider, Source: "extracted"}
+		}
+		conf.EntryPoints = append(conf.EntryPoints, EntryPoint{Type: "video", URI: link.url, Label: link.provider})
+	}
+	return conf
+}
+
+// meetingURL returns the link to join a conference by video, if any
+func meetingURL(conf *Conference) string {
+	if conf == nil {
+		return ""
+	}
+	for _, ep := range conf.EntryPoints {
+		if ep.Type == "video" {
+			return ep.URI
+		}
+	}
+	return ""
+}
+
+type meetingLink struct {
+	url      string
+	provider string
+}
+
+// extractMeetingURLs returns the distinct links to known meeting providers
+// found in the given texts, in order of appearance
+func extractMeetingURLs(texts ...string) []meetingLink {
+	var links []meetingLink
+	for _, text := range texts {
+		for _, raw := range urlPattern.FindAllString(strings.ReplaceAll(text, "&amp;", "&"), -1) {
+			raw = strings.TrimRight(raw, ".,;:!?")
+			u, err := url.Parse(raw)
+			if err != nil {
+				continue
+			}
+			provider := meetingProvider(u.Hostname())
+			if provider == "" {
+				continue
+			}
+			if !slices.ContainsFunc(links, func(link meetingLink) bool { return link.url == raw }) {
+				links = append(links, meetingLink{url: raw, provider: provider})
+			}
+		}
+	}
+	return links
+}
+
+// meetingProvider returns the meeting provider a host belongs to, if known
+func meetingProvider(host string) string {
+	host = strings.ToLower(host)
+	for {
+		if provider, ok := meetingHosts[host]; ok {
+			return provider
+		}
+		_, parent, ok := strings.Cut(host, ".")
+		if !ok || !strings.Contains(parent, ".") {
+			return ""
+		}
+		host = parent
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"testing"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// TestExtractMeetingURLs verifies meeting links are found in plain text and HTML
+func TestExtractMeetingURLs(t *testing.T) {
+	links := extractMeetingURLs(
+		"Room 4 / https://teams.microsoft.com/l/meetup-join/19%3ameeting_abc%40thread.v2/0.",
+		`<p>Join: <a href="https://us02web.zoom.us/j/812?pwd=x&amp;from=addon">here</a></p>
+		Slides: https://docs.example.com/deck, backup https://meet.jit.si/team-sync;
+		again https://us02web.zoom.us/j/812?pwd=x&from=addon`,
+	)
+
+	want := []meetingLink{
+		{url: "https://teams.microsoft.com/l/meetup-join/19%3ameeting_abc%40thread.v2/0", provider: "Microsoft Teams"},
+		{url: "https://us02web.zoom.us/j/812?pwd=x&from=addon", provider: "Zoom"},
+		{url: "https://meet.jit.si/team-sync", provider: "Jitsi Meet"},
+	}
+	if len(links) != len(want) {
+		t.Fatalf("Expected %d links, got %+v", len(want), links)
+	}
+	for i := range want {
+		if links[i] != want[i] {
+			t.Errorf("Link %d: expected %+v, got %+v", i, want[i], links[i])
+		}
+	}
+
+	if links := extractMeetingURLs("https://notzoom.us/j/1 and https://zoom.us.evil.example/j/1"); len(links) != 0 {
+		t.Errorf("Expected look-alike hosts to be ignored, got %+v", links)
+	}
+}
+
+// TestParseConference verifies the precedence of conference data, Meet links
+// and extracted links
+func TestParseConference(t *testing.T) {
+	item := &calendar.Event{
+		HangoutLink: "https://meet.google.com/abc-defg-hij",
+		Location:    "https://zoom.us/j/1",
+		ConferenceData: &calendar.ConferenceData{
+			ConferenceSolution: &calendar.ConferenceSolution{Name: "Google Meet"},
+			EntryPoints: []*calendar.EntryPoint{
+				{EntryPointType: "phone", Uri: "tel:+1-555-0100", AccessCode: "42"},
+				{EntryPointType: "video", Uri: "https://meet.google.com/abc-defg-hij", Password: "secret"},
+			},
+		},
+	}
+
+	conf := parseConference(item)
+	if conf.Source != "conference_data" || conf.Solution != "Google Meet" || len(conf.EntryPoints) != 2 {
+		t.Fatalf("Expected the conference data, got %+v", conf)
+	}
+	if conf.EntryPoints[0].PIN != "42" || conf.EntryPoints[1].Passcode != "secret" {
+		t.Errorf("Expected access codes to be mapped, got %+v", conf.EntryPoints)
+	}
+	if got := meetingURL(conf); got != "https://meet.google.com/abc-defg-hij" {
+		t.Errorf("Expected the video entry point, got %q", got)
+	}
+
+	item.ConferenceData = nil
+	if conf := parseConference(item); conf.Source != "hangout_link" || meetingURL(conf) != item.HangoutLink {
+		t.Errorf("Expected the Meet link, got %+v", conf)
+	}
+
+	item.HangoutLink = ""
+	if conf := parseConference(item); conf.Source != "extracted" || conf.Solution != "Zoom" || meetingURL(conf) != "https://zoom.us/j/1" {
+		t.Errorf("Expected the Zoom link from the location, got %+v", conf)
+	}
+
+	if conf := parseConference(&calendar.Event{Description: "No link here"}); conf != nil {
+		t.Errorf("Expected no conference, got %+v", conf)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 )
 
 // Config holds the configuration for all accounts
@@ -16,6 +17,15 @@ type Config struct {
 type AccountConfig struct {
 	Name  string `json:"name"`
 	Email string `json:"email,omitempty"`
+
+	// CalendarAliases maps short names (e.g. "team") to calendar IDs
+	CalendarAliases map[string]string `json:"calendar_aliases,omitempty"`
+
+	// TimeZone is the IANA time zone of the user (e.g. "Europe/Paris"),
+	// defaulting to the time zone of the account's Google Calendar settings,
+	// then to the zone of the first account that has one, then to the local
+	// time zone
+	TimeZone string `json:"time_zone,omitempty"`
 }
 
 // GetConfigDir returns the configuration directory path
@@ -111,11 +121,17 @@ func ListConfiguredAccounts() ([]string, error) {
 		return nil, err
 	}
 
-	accounts := make([]string, 0, len(config.Accounts))
-	for name := range config.Accounts {
+	return config.AccountNames(), nil
+}
+
+// AccountNames returns the configured account names in sorted order
+func (c *Config) AccountNames() []string {
+	accounts := make([]string, 0, len(c.Accounts))
+	for name := range c.Accounts {
 		accounts = append(accounts, name)
 	}
-	return accounts, nil
+	sort.Strings(accounts)
+	return accounts
 }
 
 // RemoveAccount removes an account and its token
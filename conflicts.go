@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// maxConflictEvents bounds the events read to look for conflicts
+const maxConflictEvents = 5000
+
+// FindConflicts returns the pairs of events that overlap or follow each other
+// without the requested buffer, across every selected calendar of the
+// requested accounts. Declined, cancelled and free (transparent) events never
+// conflict.
+func FindConflicts(ctx context.Context, provider CalendarProvider, input FindConflictsInput) (FindConflictsOutput, error) {
+	accounts, err := getTargetAccounts(provider, input.Account)
+	if err != nil {
+		return FindConflictsOutput{}, err
+	}
+	zones, err := resolveUserZones(ctx, provider, accounts, input.TimeZone)
+	if err != nil {
+		return FindConflictsOutput{}, err
+	}
+
+	events, err := listAllEvents(ctx, provider, ListEventsInput{
+		Account:         input.Account,
+		CalendarIDs:     input.Calendars,
+		AllCalendars:    len(input.Calendars) == 0,
+		TimeMin:         input.TimeMin,
+		TimeMax:         input.TimeMax,
+		ExcludeDeclined: true,
+	}, accounts, zones, maxConflictEvents)
+	if err != nil {
+		return FindConflictsOutput{}, err
+	}
+
+	busy := busyEvents(events.Events, input.IncludeAllDay)
+
+	return FindConflictsOutput{
+		Conflicts: detectConflicts(busy, time.Duration(max(input.BufferMinutes, 0))*time.Minute),
+		Checked:   len(busy),
+		TimeMin:   events.TimeMin,
+		TimeMax:   events.TimeMax,
+		TimeZone:  events.TimeZone,
+		Truncated: events.Truncated,
+		Errors:    events.Errors,
+	}, nil
+}
+
+// detectConflicts compares the pairs of events sorted by start time:
+// overlapping events are hard conflicts, and events starting less than buffer
+// after another ends (or right as it ends) are back-to-back.
+func detectConflicts(events []Event, buffer time.Duration) []Conflict {
+	var conflicts []Conflict
+	for i, a := range events {
+		for _, b := range events[i+1:] {
+			// Later events start later still, so none can conflict with a
+			gap := b.Start.Sub(a.End)
+			if gap > buffer {
+				break
+			}
+			switch {
+			case gap < 0:
+				end := a.End
+				if b.End.Before(end) {
+					end = b.End
+				}
+				conflicts = append(conflicts, Conflict{
+					Kind:    "overlap",
+					Start:   b.Start,
+					End:     end,
+					Minutes: int(end.Sub(b.Start).Minutes()),
+					Events:  []Event{a, b},
+				})
+			case gap <= buffer:
+				conflicts = append(conflicts, Conflict{
+					Kind:    "back_to_back",
+					Start:   a.End,
+					End:     b.Start,
+					Minutes: int(gap.Minutes()),
+					Events:  []Event{a, b},
+				})
+			}
+		}
+	}
+	return conflicts
+}
+
+// busyEvents returns the events that block time, keeping a single copy of
+// meetings shown in several calendars
+func busyEvents(events []Event, includeAllDay bool) []Event {
+	var busy []Event
+	for _, ev := range events {
+		if !blocksTime(ev, includeAllDay) {
+			continue
+		}
+		if !slices.ContainsFunc(busy, func(other Event) bool { return sameMeeting(ev, other) }) {
+			busy = append(busy, ev)
+		}
+	}
+	return busy
+}
+
+// blocksTime reports whether an event makes the user busy: declined,
+// cancelled and free (transparent) events and working locations do not, nor
+// do all-day events unless includeAllDay is set
+func blocksTime(ev Event, includeAllDay bool) bool {
+	return ev.Status != "cancelled" && ev.ResponseStatus != "declined" && ev.Transparency != "transparent" &&
+		ev.EventType != "workingLocation" && (!ev.AllDay || includeAllDay)
+}
+
+// sameMeeting reports whether two events are copies of the same meeting, such
+// as an invitation shown in both a work and a personal calendar
+func sameMeeting(a, b Event) bool {
+	if a.ICalUID != "" && a.ICalUID == b.ICalUID {
+		// Instances of a series share the iCalUID of the series
+		return a.Start.Equal(b.Start)
+	}
+	return a.Account == b.Account && a.ID == b.ID
+}
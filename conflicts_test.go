@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+// TestDetectConflicts verifies overlaps are measured and the buffer widens
+// what counts as back-to-back
+func TestDetectConflicts(t *testing.T) {
+	events := []Event{
+		{ID: "long", Start: utcAt(2, 9, 0), End: utcAt(2, 12, 0)},
+		{ID: "inside", Start: utcAt(2, 10, 0), End: utcAt(2, 10, 30)},
+		{ID: "after", Start: utcAt(2, 12, 10), End: utcAt(2, 13, 0)},
+		{ID: "later", Start: utcAt(2, 15, 0), End: utcAt(2, 16, 0)},
+	}
+
+	tests := []struct {
+		buffer time.Duration
+		want   []string
+	}{
+		{0, []string{"overlap long/inside 30"}},
+		{15 * time.Minute, []string{"overlap long/inside 30", "back_to_back long/after 10"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, c := range detectConflicts(events, tt.buffer) {
+			got = append(got, fmt.Sprintf("%s %s/%s %d", c.Kind, c.Events[0].ID, c.Events[1].ID, c.Minutes))
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("buffer %v: expected %v, got %v", tt.buffer, tt.want, got)
+		}
+	}
+}
+
+// TestSameMeeting verifies copies of a meeting are matched but not the other
+// instances of its series
+func TestSameMeeting(t *testing.T) {
+	a := Event{ID: "a", Account: "work", ICalUID: "weekly@example.com", Start: utcAt(2, 9, 0)}
+	copyOfA := Event{ID: "b", Account: "personal", ICalUID: "weekly@example.com", Start: utcAt(2, 9, 0)}
+	nextWeek := Event{ID: "c", Account: "work", ICalUID: "weekly@example.com", Start: utcAt(9, 9, 0)}
+
+	if !sameMeeting(a, copyOfA) || sameMeeting(a, nextWeek) || sameMeeting(a, Event{ID: "a", Account: "personal"}) {
+		t.Errorf("Unexpected meeting matching")
+	}
+}
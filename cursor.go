@@ -0,0 +1,132 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+const (
+	// cursorTTL bounds how long a continuation cursor can be used
+	cursorTTL = 30 * time.Minute
+
+	// maxCursorRead bounds the events read from a calendar to get past the
+	// position of a cursor
+	maxCursorRead = 2500
+)
+
+// cursorKey signs the cursors of this server process, so that cursors cannot
+// be forged and do not outlive a restart
+var cursorKey = func() []byte {
+	key := make([]byte, 32)
+	rand.Read(key)
+	return key
+}()
+
+// pageCursor is the state a continuation cursor carries between two calls of
+// a listing tool. Query identifies the arguments of the listing, which the
+// next call must repeat.
+type pageCursor struct {
+	Kind    string `json:"kind"`
+	Query   string `json:"query"`
+	Expires int64  `json:"expires"`
+
+	// Events and instances continue within the time range of the first call
+	TimeMin time.Time `json:"time_min,omitzero"`
+	TimeMax time.Time `json:"time_max,omitzero"`
+
+	// Events continue after the After position of the merged list, skipping
+	// the calendars in Done that have no events left
+	After *eventPosition    `json:"after,omitempty"`
+	Done  []accountCalendar `json:"done,omitempty"`
+
+	// Calendars continue at the position of each account that has calendars
+	// left, and instances at PageToken
+	Lists     map[string]listPosition `json:"lists,omitempty"`
+	PageToken string                  `json:"page_token,omitempty"`
+}
+
+// listPosition is where a listing continues: Skip entries into the page at
+// PageToken, or into the first page when there is no token
+type listPosition struct {
+	PageToken string `json:"page_token,omitempty"`
+	Skip      int    `json:"skip,omitempty"`
+}
+
+// eventPosition is the sort key of an event in a merged listing
+type eventPosition struct {
+	Start      time.Time `json:"start"`
+	End        time.Time `json:"end"`
+	AllDay     bool      `json:"all_day,omitempty"`
+	Account    string    `json:"account"`
+	CalendarID string    `json:"calendar_id"`
+	ID         string    `json:"id"`
+}
+
+// accountCalendar identifies a calendar of an account
+type accountCalendar struct {
+	Account    string `json:"account"`
+	CalendarID string `json:"calendar_id"`
+}
+
+func positionOf(ev Event) *eventPosition {
+	return &eventPosition{Start: ev.Start, End: ev.End, AllDay: ev.AllDay, Account: ev.Account, CalendarID: ev.CalendarID, ID: ev.ID}
+}
+
+// event returns an event that sorts at the position
+func (p eventPosition) event() Event {
+	return Event{Start: p.Start, End: p.End, AllDay: p.AllDay, Account: p.Account, CalendarID: p.CalendarID, ID: p.ID}
+}
+
+// queryHash identifies the arguments of a listing, given with its cursor and
+// page size cleared
+func queryHash(input any) string {
+	data, _ := json.Marshal(input)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:16])
+}
+
+// encodeCursor returns the opaque, signed form of a cursor, valid for cursorTTL
+func encodeCursor(c pageCursor, now time.Time) string {
+	c.Expires = now.Add(cursorTTL).Unix()
+	data, _ := json.Marshal(c)
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	return payload + "." + base64.RawURLEncoding.EncodeToString(cursorMAC(payload))
+}
+
+// decodeCursor verifies a cursor was issued by this server for the same kind
+// of listing and the same query, and has not expired
+func decodeCursor(token, kind, query string, now time.Time) (pageCursor, error) {
+	payload, sig, ok := strings.Cut(token, ".")
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if !ok || err != nil || !hmac.Equal(mac, cursorMAC(payload)) {
+		return pageCursor{}, errors.New("invalid cursor")
+	}
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return pageCursor{}, errors.New("invalid cursor")
+	}
+	var c pageCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.Kind != kind {
+		return pageCursor{}, errors.New("invalid cursor")
+	}
+	if now.Unix() > c.Expires {
+		return pageCursor{}, errors.New("cursor expired - run the query again without it")
+	}
+	if c.Query != query {
+		return pageCursor{}, errors.New("cursor does not match the query - pass the same arguments as the call that returned it")
+	}
+	return c, nil
+}
+
+func cursorMAC(payload string) []byte {
+	h := hmac.New(sha256.New, cursorKey)
+	h.Write([]byte(payload))
+	return h.Sum(nil)
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestDecodeCursor verifies cursors are bound to their listing, query and lifetime
+func TestDecodeCursor(t *testing.T) {
+	now := time.Date(2026, 2, 2, 12, 0, 0, 0, time.UTC)
+	token := encodeCursor(pageCursor{Kind: "calendars", Query: "q1", Lists: map[string]listPosition{"work": {Skip: 3}}}, now)
+
+	cursor, err := decodeCursor(token, "calendars", "q1", now.Add(cursorTTL))
+	if err != nil || cursor.Lists["work"].Skip != 3 {
+		t.Fatalf("decodeCursor() = %+v, %v", cursor, err)
+	}
+
+	payload, sig, _ := strings.Cut(token, ".")
+	forged := encodeCursor(pageCursor{Kind: "calendars", Query: "q1", Lists: map[string]listPosition{"work": {Skip: 100}}}, now)
+	forgedPayload, _, _ := strings.Cut(forged, ".")
+
+	tests := []struct {
+		name  string
+		token string
+		kind  string
+		query string
+		now   time.Time
+		want  string
+	}{
+		{"garbage", "not-a-cursor", "calendars", "q1", now, "invalid cursor"},
+		{"tampered", forgedPayload + "." + sig, "calendars", "q1", now, "invalid cursor"},
+		{"truncated", payload, "calendars", "q1", now, "invalid cursor"},
+		{"other listing", token, "events", "q1", now, "invalid cursor"},
+		{"other query", token, "calendars", "q2", now, "does not match the query"},
+		{"expired", token, "calendars", "q1", now.Add(cursorTTL + time.Second), "cursor expired"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decodeCursor(tt.token, tt.kind, tt.query, tt.now)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// eventTypes are the event types of the Calendar API
+var eventTypes = []string{"default", "birthday", "focusTime", "fromGmail", "outOfOffice", "workingLocation"}
+
+// eventTypeLabels name the special event types in text output
+var eventTypeLabels = map[string]string{
+	"birthday":        "birthday",
+	"focusTime":       "focus time",
+	"fromGmail":       "from Gmail",
+	"outOfOffice":     "out of office",
+	"workingLocation": "working location",
+}
+
+// checkEventTypes verifies every requested event type exists
+func checkEventTypes(types []string) error {
+	for _, t := range types {
+		if !slices.Contains(eventTypes, t) {
+			return fmt.Errorf("invalid event type '%s' (expected one of %s)", t, strings.Join(eventTypes, ", "))
+		}
+	}
+	return nil
+}
+
+// parseWorkingLocation returns where a working location event says the user
+// works, labelled with the office or custom label, else the event's summary
+func parseWorkingLocation(item *calendar.Event) *WorkingLocation {
+	props := item.WorkingLocationProperties
+	if props == nil {
+		return nil
+	}
+
+	loc := &WorkingLocation{Type: props.Type}
+	switch {
+	case props.OfficeLocation != nil:
+		office := props.OfficeLocation
+		loc.Type = cmp.Or(loc.Type, "officeLocation")
+		loc.Label = cmp.Or(office.Label, office.BuildingId)
+		loc.BuildingID = office.BuildingId
+		loc.FloorID = office.FloorId
+		loc.FloorSectionID = office.FloorSectionId
+		loc.DeskID = office.DeskId
+	case props.CustomLocation != nil:
+		loc.Type = cmp.Or(loc.Type, "customLocation")
+		loc.Label = props.CustomLocation.Label
+	case props.HomeOffice != nil:
+		loc.Type = cmp.Or(loc.Type, "homeOffice")
+		loc.Label = "Home"
+	}
+	loc.Label = cmp.Or(loc.Label, item.Summary)
+	return loc
+}
+
+// parseStatusProperties returns the auto-decline settings of out of office
+// and focus time events
+func parseStatusProperties(item *calendar.Event) (outOfOffice, focusTime *StatusProperties) {
+	if props := item.OutOfOfficeProperties; props != nil {
+		outOfOffice = &StatusProperties{
+			AutoDeclineMode: props.AutoDeclineMode,
+			DeclineMessage:  props.DeclineMessage,
+		}
+	}
+	if props := item.FocusTimeProperties; props != nil {
+		focusTime = &StatusProperties{
+			AutoDeclineMode: props.AutoDeclineMode,
+			DeclineMessage:  props.DeclineMessage,
+			ChatStatus:      props.ChatStatus,
+		}
+	}
+	return outOfOffice, focusTime
+}
+
+// formatWorkingLocation renders a working location, e.g. "Paris office (floor 3, desk 12)"
+func formatWorkingLocation(loc WorkingLocation) string {
+	var details []string
+	if loc.FloorID != "" {
+		details = append(details, "floor "+loc.FloorID)
+	}
+	if loc.FloorSectionID != "" {
+		details = append(details, "section "+loc.FloorSectionID)
+	}
+	if loc.DeskID != "" {
+		details = append(details, "desk "+loc.DeskID)
+	}
+	text := cmp.Or(loc.Label, loc.Type)
+	if len(details) > 0 {
+		text += " (" + strings.Join(details, ", ") + ")"
+	}
+	return text
+}
+
+// GetWorkingLocation returns, for each day of the requested period, where the
+// user's working location events say they work and whether they are out of
+// office. Working locations are read from the primary calendar of each account.
+func GetWorkingLocation(ctx context.Context, provider CalendarProvider, input WorkingLocationInput) (WorkingLocationOutput, error) {
+	return getWorkingLocation(ctx, provider, input, time.Now())
+}
+
+func getWorkingLocation(ctx context.Context, provider CalendarProvider, input WorkingLocationInput, now time.Time) (WorkingLocationOutput, error) {
+	accounts, err := getTargetAccounts(provider, input.Account)
+	if err != nil {
+		return WorkingLocationOutput{}, err
+	}
+	zones, err := resolveUserZones(ctx, provider, accounts, input.TimeZone)
+	if err != nil {
+		return WorkingLocationOutput{}, err
+	}
+	loc := zones.display
+
+	// A single instant, such as "now", stands for its whole day
+	span := days(startOfDay(now.In(loc)), 1)
+	if strings.TrimSpace(input.Date) != "" {
+		span, err = parseTimeExpr(input.Date, now, now, loc, zones.firstDay)
+		if err != nil {
+			return WorkingLocationOutput{}, fmt.Errorf("invalid date format: %w", err)
+		}
+	}
+	start := startOfDay(span.Start)
+	end := endOfDay(span.Start)
+	if span.End.After(end) {
+		end = span.End
+	}
+	numDays := daysBetween(start, end)
+
+	events, err := listEvents(ctx, provider, ListEventsInput{
+		Account:    input.Account,
+		TimeMin:    start.Format(time.RFC3339),
+		TimeMax:    end.Format(time.RFC3339),
+		MaxResults: maxListResults,
+		EventTypes: []string{"workingLocation", "outOfOffice"},
+	}, accounts, zones, maxListResults)
+	if err != nil {
+		return WorkingLocationOutput{}, err
+	}
+
+	output := WorkingLocationOutput{
+		TimeMin:  start,
+		TimeMax:  end,
+		TimeZone: events.TimeZone,
+		Errors:   events.Errors,
+	}
+	for i := range numDays {
+		dayStart := start.AddDate(0, 0, i)
+		dayEnd := start.AddDate(0, 0, i+1)
+		day := WorkingDay{
+			Date:      dayStart.Format("2006-01-02"),
+			Weekday:   dayStart.Weekday().String(),
+			Locations: []WorkingLocationEntry{},
+		}
+		for _, ev := range events.Events {
+			first, last := eventDays(ev, loc)
+			if first.After(dayStart) || last.Before(dayStart) {
+				continue
+			}
+			switch {
+			case ev.EventType == "outOfOffice":
+				day.OutOfOffice = true
+			case ev.WorkingLocation != nil:
+				entry := WorkingLocationEntry{Account: ev.Account, Location: *ev.WorkingLocation, AllDay: ev.AllDay}
+				// Part-day locations keep the hours they cover on this day
+				if !ev.AllDay {
+					hours, _ := clipPeriod(ev.Start, ev.End, dayStart, dayEnd)
+					entry.Start, entry.End = hours.Start, hours.End
+				}
+				day.Locations = append(day.Locations, entry)
+			}
+		}
+		output.Days = append(output.Days, day)
+	}
+
+	return output, nil
+}
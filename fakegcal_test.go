@@ -0,0 +1,777 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/option"
+)
+
+// fakeCalendarAPI is an in-process stand-in for the subset of the Google
+// Calendar v3 REST API used by GoogleProvider: calendarList.list/get,
+// events.list/get/instances, freebusy.query, settings.list and colors.get.
+// Each fake serves a single account.
+type fakeCalendarAPI struct {
+	mu sync.Mutex
+
+	// calendars is the account's calendar list; the entry with Primary set
+	// answers to the "primary" alias
+	calendars []*calendar.CalendarListEntry
+
+	// events holds the (already expanded) events keyed by calendar ID
+	events map[string][]*calendar.Event
+
+	// series holds the masters of recurring events keyed by calendar ID;
+	// their instances are seeded in events with RecurringEventId set
+	series map[string][]*calendar.Event
+
+	// pageSize caps the number of items per page when the client does not
+	// ask for fewer; zero means the API default of 250
+	pageSize int
+
+	// failWith, when set, makes every request fail with that HTTP status
+	failWith int
+
+	// aliases is the calendar_aliases configuration of the account
+	aliases map[string]string
+
+	// timeZone is the time_zone configuration of the account
+	timeZone string
+
+	// settings are the user settings of the account, keyed by setting ID
+	settings map[string]string
+
+	// freeBusy overrides the free/busy answer for the given calendar IDs
+	freeBusy map[string]calendar.FreeBusyCalendar
+
+	// requests records the method, path and query of every request received
+	requests []string
+}
+
+// newFakeCalendarAPI returns a fake seeded with the given calendars and no events
+func newFakeCalendarAPI(calendars ...*calendar.CalendarListEntry) *fakeCalendarAPI {
+	return &fakeCalendarAPI{
+		calendars: calendars,
+		events:    make(map[string][]*calendar.Event),
+		series:    make(map[string][]*calendar.Event),
+	}
+}
+
+// addEvents seeds events on a calendar
+func (f *fakeCalendarAPI) addEvents(calendarID string, events ...*calendar.Event) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.events[calendarID] = append(f.events[calendarID], events...)
+}
+
+// addSeries seeds the master of a recurring event and its instances on a calendar
+func (f *fakeCalendarAPI) addSeries(calendarID string, master *calendar.Event, instances ...*calendar.Event) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.series[calendarID] = append(f.series[calendarID], master)
+	f.events[calendarID] = append(f.events[calendarID], instances...)
+}
+
+// requestLog returns a copy of the recorded requests
+func (f *fakeCalendarAPI) requestLog() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.requests)
+}
+
+// eventRequests returns the recorded event listings of a fake
+func eventRequests(f *fakeCalendarAPI) []string {
+	return slices.DeleteFunc(f.requestLog(), func(req string) bool {
+		return !strings.HasPrefix(req, "GET /calendars/") || !strings.Contains(req, "/events?")
+	})
+}
+
+// start serves the fake on a local test server and returns a client for it
+func (f *fakeCalendarAPI) start(t *testing.T) *calendar.Service {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/me/calendarList", f.handleCalendarList)
+	mux.HandleFunc("GET /users/me/calendarList/{calendarId}", f.handleCalendarGet)
+	mux.HandleFunc("GET /calendars/{calendarId}/events", f.handleEventsList)
+	mux.HandleFunc("GET /calendars/{calendarId}/events/{eventId}", f.handleEventGet)
+	mux.HandleFunc("GET /calendars/{calendarId}/events/{eventId}/instances", f.handleEventInstances)
+	mux.HandleFunc("POST /freeBusy", f.handleFreeBusy)
+	mux.HandleFunc("GET /users/me/settings", f.handleSettingsList)
+	mux.HandleFunc("GET /colors", f.handleColors)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		f.requests = append(f.requests, r.Method+" "+r.URL.Path+"?"+r.URL.RawQuery)
+		failWith := f.failWith
+		f.mu.Unlock()
+
+		if failWith != 0 {
+			writeAPIError(w, failWith, http.StatusText(failWith))
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	svc, err := calendar.NewService(context.Background(),
+		option.WithEndpoint(srv.URL+"/"),
+		option.WithHTTPClient(srv.Client()),
+	)
+	if err != nil {
+		t.Fatalf("failed to create calendar service: %v", err)
+	}
+	return svc
+}
+
+// resolveCalendar maps the "primary" alias to the primary calendar ID
+func (f *fakeCalendarAPI) resolveCalendar(id string) (*calendar.CalendarListEntry, bool) {
+	for _, cal := range f.calendars {
+		if cal.Id == id || (id == "primary" && cal.Primary) {
+			return cal, true
+		}
+	}
+	return nil, false
+}
+
+func (f *fakeCalendarAPI) handleCalendarList(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// Like the API, hidden and deleted calendars are left out unless asked for
+	q := r.URL.Query()
+	roles := []string{"freeBusyReader", "reader", "writer", "owner"}
+	var listed []*calendar.CalendarListEntry
+	for _, cal := range f.calendars {
+		if (cal.Hidden && q.Get("showHidden") != "true") || (cal.Deleted && q.Get("showDeleted") != "true") {
+			continue
+		}
+		if role := q.Get("minAccessRole"); role != "" && slices.Index(roles, cal.AccessRole) < slices.Index(roles, role) {
+			continue
+		}
+		listed = append(listed, cal)
+	}
+
+	bounds, next, err := f.page(r, len(listed))
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, r, &calendar.CalendarList{
+		Kind:          "calendar#calendarList",
+		Items:         listed[bounds[0]:bounds[1]],
+		NextPageToken: next,
+	})
+}
+
+func (f *fakeCalendarAPI) handleCalendarGet(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	cal, ok := f.resolveCalendar(r.PathValue("calendarId"))
+	if !ok {
+		writeAPIError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	writeJSON(w, r, cal)
+}
+
+func (f *fakeCalendarAPI) handleEventsList(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	cal, ok := f.resolveCalendar(r.PathValue("calendarId"))
+	if !ok {
+		writeAPIError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	q := r.URL.Query()
+	timeMin, err := parseQueryTime(q.Get("timeMin"))
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "Bad Request")
+		return
+	}
+	timeMax, err := parseQueryTime(q.Get("timeMax"))
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "Bad Request")
+		return
+	}
+
+	// Like the API, only expanded instances can be ordered by start time
+	singleEvents := q.Get("singleEvents") == "true"
+	if q.Get("orderBy") == "startTime" && !singleEvents {
+		writeAPIError(w, http.StatusBadRequest, "The requested ordering is not available for the particular query.")
+		return
+	}
+
+	// Without expansion, series masters replace their instances
+	candidates := f.events[cal.Id]
+	if !singleEvents {
+		candidates = slices.Concat(
+			slices.DeleteFunc(slices.Clone(candidates), func(ev *calendar.Event) bool { return ev.RecurringEventId != "" }),
+			f.series[cal.Id],
+		)
+	}
+
+	var matched []*calendar.Event
+	for _, ev := range candidates {
+		start, end := fakeEventBounds(ev)
+		if len(ev.Recurrence) > 0 {
+			// A series master spans until its last instance
+			end = time.Time{}
+		}
+		if !timeMax.IsZero() && !start.Before(timeMax) {
+			continue
+		}
+		if !timeMin.IsZero() && !end.IsZero() && !end.After(timeMin) {
+			continue
+		}
+		if text := q.Get("q"); text != "" && !matchesText(ev, text) {
+			continue
+		}
+		if types := q["eventTypes"]; len(types) > 0 && !slices.Contains(types, cmp.Or(ev.EventType, "default")) {
+			continue
+		}
+		matched = append(matched, ev)
+	}
+
+	if q.Get("orderBy") == "startTime" {
+		slices.SortStableFunc(matched, func(a, b *calendar.Event) int {
+			sa, _ := fakeEventBounds(a)
+			sb, _ := fakeEventBounds(b)
+			return sa.Compare(sb)
+		})
+	}
+
+	bounds, next, err := f.page(r, len(matched))
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, r, &calendar.Events{
+		Kind:          "calendar#events",
+		Summary:       cal.Summary,
+		TimeZone:      cal.TimeZone,
+		Items:         matched[bounds[0]:bounds[1]],
+		NextPageToken: next,
+	})
+}
+
+func (f *fakeCalendarAPI) handleEventGet(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	cal, ok := f.resolveCalendar(r.PathValue("calendarId"))
+	if !ok {
+		writeAPIError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	for _, ev := range slices.Concat(f.events[cal.Id], f.series[cal.Id]) {
+		if ev.Id == r.PathValue("eventId") {
+			writeJSON(w, r, ev)
+			return
+		}
+	}
+	writeAPIError(w, http.StatusNotFound, "Not Found")
+}
+
+func (f *fakeCalendarAPI) handleEventInstances(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	cal, ok := f.resolveCalendar(r.PathValue("calendarId"))
+	if !ok || !slices.ContainsFunc(f.series[cal.Id], func(ev *calendar.Event) bool { return ev.Id == r.PathValue("eventId") }) {
+		writeAPIError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	q := r.URL.Query()
+	timeMin, err := parseQueryTime(q.Get("timeMin"))
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "Bad Request")
+		return
+	}
+	timeMax, err := parseQueryTime(q.Get("timeMax"))
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "Bad Request")
+		return
+	}
+
+	var matched []*calendar.Event
+	for _, ev := range f.events[cal.Id] {
+		start, end := fakeEventBounds(ev)
+		if ev.RecurringEventId != r.PathValue("eventId") ||
+			(!timeMax.IsZero() && !start.Before(timeMax)) || (!timeMin.IsZero() && !end.After(timeMin)) {
+			continue
+		}
+		matched = append(matched, ev)
+	}
+	slices.SortStableFunc(matched, func(a, b *calendar.Event) int {
+		sa, _ := fakeEventBounds(a)
+		sb, _ := fakeEventBounds(b)
+		return sa.Compare(sb)
+	})
+
+	bounds, next, err := f.page(r, len(matched))
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, r, &calendar.Events{
+		Kind:          "calendar#events",
+		Summary:       cal.Summary,
+		TimeZone:      cal.TimeZone,
+		Items:         matched[bounds[0]:bounds[1]],
+		NextPageToken: next,
+	})
+}
+
+func (f *fakeCalendarAPI) handleSettingsList(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var items []*calendar.Setting
+	for _, id := range slices.Sorted(maps.Keys(f.settings)) {
+		items = append(items, &calendar.Setting{Kind: "calendar#setting", Id: id, Value: f.settings[id]})
+	}
+	bounds, next, err := f.page(r, len(items))
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, r, &calendar.Settings{
+		Kind:          "calendar#settings",
+		Items:         items[bounds[0]:bounds[1]],
+		NextPageToken: next,
+	})
+}
+
+// fakeColors is a subset of the palettes of the API
+var fakeColors = &calendar.Colors{
+	Kind: "calendar#colors",
+	Calendar: map[string]calendar.ColorDefinition{
+		"1":  {Background: "#ac725e", Foreground: "#1d1d1d"},
+		"14": {Background: "#9fe1e7", Foreground: "#1d1d1d"},
+		"24": {Background: "#a47ae2", Foreground: "#1d1d1d"},
+	},
+	Event: map[string]calendar.ColorDefinition{
+		"1":  {Background: "#a4bdfc", Foreground: "#1d1d1d"},
+		"2":  {Background: "#7ae7bf", Foreground: "#1d1d1d"},
+		"10": {Background: "#51b749", Foreground: "#1d1d1d"},
+		"11": {Background: "#dc2127", Foreground: "#1d1d1d"},
+	},
+}
+
+func (f *fakeCalendarAPI) handleColors(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, r, fakeColors)
+}
+
+func (f *fakeCalendarAPI) handleFreeBusy(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var req calendar.FreeBusyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "Bad Request")
+		return
+	}
+	timeMin, err := time.Parse(time.RFC3339, req.TimeMin)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "Bad Request")
+		return
+	}
+	timeMax, err := time.Parse(time.RFC3339, req.TimeMax)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "Bad Request")
+		return
+	}
+
+	resp := &calendar.FreeBusyResponse{
+		Kind:      "calendar#freeBusy",
+		TimeMin:   req.TimeMin,
+		TimeMax:   req.TimeMax,
+		Calendars: make(map[string]calendar.FreeBusyCalendar),
+	}
+	for _, item := range req.Items {
+		if answer, ok := f.freeBusy[item.Id]; ok {
+			resp.Calendars[item.Id] = answer
+			continue
+		}
+
+		cal, ok := f.resolveCalendar(item.Id)
+		if !ok {
+			resp.Calendars[item.Id] = calendar.FreeBusyCalendar{
+				Errors: []*calendar.Error{{Domain: "global", Reason: "notFound"}},
+			}
+			continue
+		}
+
+		var busy []*calendar.TimePeriod
+		for _, ev := range f.events[cal.Id] {
+			if ev.Status == "cancelled" || ev.Transparency == "transparent" {
+				continue
+			}
+			start, end := fakeEventBounds(ev)
+			if !start.Before(timeMax) || !end.After(timeMin) {
+				continue
+			}
+			busy = append(busy, &calendar.TimePeriod{
+				Start: start.UTC().Format(time.RFC3339),
+				End:   end.UTC().Format(time.RFC3339),
+			})
+		}
+		resp.Calendars[item.Id] = calendar.FreeBusyCalendar{Busy: busy}
+	}
+	writeJSON(w, r, resp)
+}
+
+// page returns the [start, end) bounds of the requested page over n items
+// and the token of the following page, using the offset as page token
+func (f *fakeCalendarAPI) page(r *http.Request, n int) ([2]int, string, error) {
+	size := f.pageSize
+	if size <= 0 {
+		size = 250
+	}
+	if v := r.URL.Query().Get("maxResults"); v != "" {
+		max, err := strconv.Atoi(v)
+		if err != nil || max <= 0 {
+			return [2]int{}, "", fmt.Errorf("invalid maxResults %q", v)
+		}
+		size = min(size, max)
+	}
+
+	start := 0
+	if v := r.URL.Query().Get("pageToken"); v != "" {
+		offset, err := strconv.Atoi(v)
+		if err != nil || offset < 0 || offset > n {
+			return [2]int{}, "", fmt.Errorf("invalid pageToken %q", v)
+		}
+		start = offset
+	}
+
+	end := min(start+size, n)
+	next := ""
+	if end < n {
+		next = strconv.Itoa(end)
+	}
+	return [2]int{start, end}, next, nil
+}
+
+// fakeEventBounds returns an event's start and end, treating all-day dates as UTC midnight
+func fakeEventBounds(ev *calendar.Event) (time.Time, time.Time) {
+	parse := func(dt *calendar.EventDateTime) time.Time {
+		if dt == nil {
+			return time.Time{}
+		}
+		if dt.DateTime != "" {
+			t, _ := time.Parse(time.RFC3339, dt.DateTime)
+			return t
+		}
+		t, _ := time.Parse("2006-01-02", dt.Date)
+		return t
+	}
+	return parse(ev.Start), parse(ev.End)
+}
+
+func matchesText(ev *calendar.Event, text string) bool {
+	text = strings.ToLower(text)
+	for _, field := range []string{ev.Summary, ev.Description, ev.Location} {
+		if strings.Contains(strings.ToLower(field), text) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseQueryTime(v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
+
+// writeJSON writes a response body, keeping only the fields selected by the
+// request's fields parameter like the API's partial responses
+func writeJSON(w http.ResponseWriter, r *http.Request, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	selector := r.URL.Query().Get("fields")
+	if selector == "" {
+		json.NewEncoder(w).Encode(v)
+		return
+	}
+
+	data, _ := json.Marshal(v)
+	var body any
+	json.Unmarshal(data, &body)
+	json.NewEncoder(w).Encode(selectFields(body, selector))
+}
+
+// selectFields keeps the fields of a decoded JSON value named by a partial
+// response selector such as "nextPageToken,items(id,attendees(email))"
+func selectFields(v any, selector string) any {
+	switch v := v.(type) {
+	case []any:
+		for i := range v {
+			v[i] = selectFields(v[i], selector)
+		}
+		return v
+	case map[string]any:
+		kept := make(map[string]any)
+		depth, start := 0, 0
+		for i := 0; i <= len(selector); i++ {
+			if i < len(selector) {
+				switch selector[i] {
+				case '(':
+					depth++
+					continue
+				case ')':
+					depth--
+					continue
+				case ',':
+					if depth > 0 {
+						continue
+					}
+				default:
+					continue
+				}
+			}
+			name, sub, _ := strings.Cut(selector[start:i], "(")
+			if value, ok := v[name]; ok {
+				if sub != "" {
+					value = selectFields(value, strings.TrimSuffix(sub, ")"))
+				}
+				kept[name] = value
+			}
+			start = i + 1
+		}
+		return kept
+	default:
+		return v
+	}
+}
+
+// writeAPIError writes an error body shaped like the Google API's JSON errors
+func writeAPIError(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]any{
+		"error": map[string]any{
+			"code":    code,
+			"message": message,
+			"errors": []map[string]string{
+				{"domain": "global", "reason": strings.ToLower(strings.ReplaceAll(http.StatusText(code), " ", "")), "message": message},
+			},
+		},
+	})
+}
+
+// newFakeGoogleProvider returns a GoogleProvider whose accounts are served by the given fakes
+func newFakeGoogleProvider(t *testing.T, accounts map[string]*fakeCalendarAPI) *GoogleProvider {
+	t.Helper()
+
+	config := &Config{Accounts: make(map[string]AccountConfig)}
+	services := make(map[string]*calendar.Service, len(accounts))
+	for name, api := range accounts {
+		config.Accounts[name] = AccountConfig{Name: name, CalendarAliases: api.aliases, TimeZone: api.timeZone}
+		services[name] = api.start(t)
+	}
+
+	return &GoogleProvider{
+		loadConfig: func() (*Config, error) {
+			return config, nil
+		},
+		newService: func(ctx context.Context, account string) (*calendar.Service, error) {
+			srv, ok := services[account]
+			if !ok {
+				return nil, fmt.Errorf("failed to load token for account '%s': no such account", account)
+			}
+			return srv, nil
+		},
+	}
+}
+
+// seededFakeAccounts returns a "personal" and a "work" account with a few
+// realistic fixtures in the week of 2026-02-02
+func seededFakeAccounts() map[string]*fakeCalendarAPI {
+	personal := newFakeCalendarAPI(
+		&calendar.CalendarListEntry{Id: "me@example.com", Summary: "me@example.com", Primary: true, AccessRole: "owner", TimeZone: "Europe/Paris", Selected: true},
+		&calendar.CalendarListEntry{Id: "fr.french#holiday@group.v.calendar.google.com", Summary: "Holidays in France", AccessRole: "reader", TimeZone: "Europe/Paris", Selected: true},
+	)
+	personal.addEvents("me@example.com",
+		&calendar.Event{
+			Id:        "dentist",
+			Summary:   "Dentist",
+			Location:  "12 rue de la Paix, Paris",
+			Status:    "confirmed",
+			HtmlLink:  "https://www.google.com/calendar/event?eid=dentist",
+			Start:     &calendar.EventDateTime{DateTime: "2026-02-03T09:00:00+01:00", TimeZone: "Europe/Paris"},
+			End:       &calendar.EventDateTime{DateTime: "2026-02-03T09:45:00+01:00", TimeZone: "Europe/Paris"},
+			Organizer: &calendar.EventOrganizer{Email: "me@example.com", Self: true},
+		},
+		&calendar.Event{
+			Id:           "ski-trip",
+			Summary:      "Ski trip",
+			Status:       "confirmed",
+			HtmlLink:     "https://www.google.com/calendar/event?eid=ski-trip",
+			Start:        &calendar.EventDateTime{Date: "2026-02-06"},
+			End:          &calendar.EventDateTime{Date: "2026-02-09"},
+			Transparency: "transparent",
+		},
+	)
+	personal.addEvents("fr.french#holiday@group.v.calendar.google.com",
+		&calendar.Event{
+			Id:      "holiday",
+			Summary: "Chandeleur",
+			Status:  "confirmed",
+			Start:   &calendar.EventDateTime{Date: "2026-02-02"},
+			End:     &calendar.EventDateTime{Date: "2026-02-03"},
+		},
+	)
+
+	work := newFakeCalendarAPI(
+		&calendar.CalendarListEntry{Id: "me@work.example.com", Summary: "me@work.example.com", Primary: true, AccessRole: "owner", TimeZone: "America/New_York", Selected: true},
+		&calendar.CalendarListEntry{Id: "team@group.calendar.google.com", Summary: "Team", AccessRole: "writer", TimeZone: "America/New_York", Selected: true},
+		&calendar.CalendarListEntry{Id: "oncall@group.calendar.google.com", Summary: "On-call rotation", AccessRole: "reader", TimeZone: "America/New_York"},
+	)
+	// The standup is a weekly series of four, the third one moved to Tuesday
+	standup := func(id, start, end, original string) *calendar.Event {
+		return &calendar.Event{
+			Id:                id,
+			Summary:           "Standup",
+			Description:       "Daily sync",
+			Status:            "confirmed",
+			HtmlLink:          "https://www.google.com/calendar/event?eid=standup",
+			Start:             &calendar.EventDateTime{DateTime: start, TimeZone: "America/New_York"},
+			End:               &calendar.EventDateTime{DateTime: end, TimeZone: "America/New_York"},
+			Organizer:         &calendar.EventOrganizer{Email: "lead@work.example.com"},
+			RecurringEventId:  "standup",
+			OriginalStartTime: &calendar.EventDateTime{DateTime: original, TimeZone: "America/New_York"},
+			HangoutLink:       "https://meet.google.com/abc-defg-hij",
+			ConferenceData: &calendar.ConferenceData{
+				ConferenceId:       "abc-defg-hij",
+				ConferenceSolution: &calendar.ConferenceSolution{Name: "Google Meet", Key: &calendar.ConferenceSolutionKey{Type: "hangoutsMeet"}},
+				EntryPoints: []*calendar.EntryPoint{
+					{EntryPointType: "video", Uri: "https://meet.google.com/abc-defg-hij", Label: "meet.google.com/abc-defg-hij"},
+					{EntryPointType: "phone", Uri: "tel:+1-555-0100", Label: "+1 555-0100", Pin: "123456789", RegionCode: "US"},
+					{EntryPointType: "sip", Uri: "sip:123456789@gmeet.redial.example.com", Pin: "123456789"},
+				},
+			},
+			Attendees: []*calendar.EventAttendee{
+				{Email: "lead@work.example.com", Organizer: true, ResponseStatus: "accepted"},
+				{Email: "me@work.example.com", Self: true, ResponseStatus: "accepted"},
+			},
+		}
+	}
+	master := standup("standup", "2026-02-02T09:30:00-05:00", "2026-02-02T09:45:00-05:00", "")
+	master.RecurringEventId, master.OriginalStartTime = "", nil
+	master.Recurrence = []string{"RRULE:FREQ=WEEKLY;BYDAY=MO;COUNT=4"}
+	work.addSeries("me@work.example.com", master,
+		standup("standup-20260202", "2026-02-02T09:30:00-05:00", "2026-02-02T09:45:00-05:00", "2026-02-02T09:30:00-05:00"),
+		standup("standup-20260209", "2026-02-09T09:30:00-05:00", "2026-02-09T09:45:00-05:00", "2026-02-09T09:30:00-05:00"),
+		standup("standup-20260216", "2026-02-17T09:30:00-05:00", "2026-02-17T09:45:00-05:00", "2026-02-16T09:30:00-05:00"),
+		standup("standup-20260223", "2026-02-23T09:30:00-05:00", "2026-02-23T09:45:00-05:00", "2026-02-23T09:30:00-05:00"),
+	)
+	work.addEvents("me@work.example.com",
+		&calendar.Event{
+			Id:          "review-20260203",
+			Summary:     "Design review",
+			Description: `Join <a href="https://us02web.zoom.us/j/8123456789?pwd=xyz&amp;from=addon">Zoom</a>. Agenda: https://docs.example.com/review`,
+			Status:      "confirmed",
+			HtmlLink:    "https://www.google.com/calendar/event?eid=review",
+			Start:       &calendar.EventDateTime{DateTime: "2026-02-03T03:00:00-05:00", TimeZone: "America/New_York"},
+			End:         &calendar.EventDateTime{DateTime: "2026-02-03T04:00:00-05:00", TimeZone: "America/New_York"},
+			Attendees: []*calendar.EventAttendee{
+				{Email: "lead@work.example.com", Organizer: true, ResponseStatus: "accepted"},
+				{Email: "me@work.example.com", Self: true, ResponseStatus: "needsAction"},
+				{Email: "alice@work.example.com", DisplayName: "Alice Martin", Optional: true, ResponseStatus: "tentative", AdditionalGuests: 1},
+				{Email: "bob@work.example.com", ResponseStatus: "declined", Comment: "On leave"},
+				{Email: "room-4@resource.calendar.google.com", DisplayName: "Room 4", Resource: true, ResponseStatus: "accepted"},
+			},
+		},
+	)
+	work.addEvents("team@group.calendar.google.com",
+		&calendar.Event{
+			Id:      "offsite",
+			Summary: "Team offsite",
+			Status:  "confirmed",
+			Start:   &calendar.EventDateTime{Date: "2026-02-04"},
+			End:     &calendar.EventDateTime{Date: "2026-02-05"},
+			Attendees: []*calendar.EventAttendee{
+				{Email: "lead@work.example.com", Organizer: true, ResponseStatus: "accepted"},
+				{Email: "me@work.example.com", Self: true, ResponseStatus: "declined"},
+			},
+		},
+	)
+
+	work.addEvents("oncall@group.calendar.google.com",
+		&calendar.Event{
+			Id:      "oncall-week6",
+			Summary: "On call: me",
+			Status:  "confirmed",
+			Start:   &calendar.EventDateTime{Date: "2026-02-02"},
+			End:     &calendar.EventDateTime{Date: "2026-02-09"},
+		},
+	)
+
+	work.aliases = map[string]string{"pager": "oncall@group.calendar.google.com"}
+
+	personal.timeZone = "Europe/Paris"
+	work.timeZone = "America/New_York"
+
+	return map[string]*fakeCalendarAPI{"personal": personal, "work": work}
+}
+
+// callTool runs a tool through a full MCP client/server session
+func callTool(t *testing.T, provider CalendarProvider, name string, args map[string]any) *mcp.CallToolResult {
+	t.Helper()
+	ctx := context.Background()
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := NewCalendarServer(provider).Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("server connect failed: %v", err)
+	}
+	t.Cleanup(func() { serverSession.Close() })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "0.0.1"}, nil)
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client connect failed: %v", err)
+	}
+	t.Cleanup(func() { clientSession.Close() })
+
+	res, err := clientSession.CallTool(ctx, &mcp.CallToolParams{Name: name, Arguments: args})
+	if err != nil {
+		t.Fatalf("CallTool(%s) failed: %v", name, err)
+	}
+	return res
+}
+
+// structuredOutput decodes a tool result's structured content into out
+func structuredOutput(t *testing.T, res *mcp.CallToolResult, out any) {
+	t.Helper()
+	if res.IsError {
+		t.Fatalf("tool returned an error: %s", resultText(t, res))
+	}
+	data, err := json.Marshal(res.StructuredContent)
+	if err != nil {
+		t.Fatalf("failed to marshal structured content: %v", err)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		t.Fatalf("failed to decode structured content %s: %v", data, err)
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// eventField is an optional group of Event fields: the Calendar API fields
+// it is read from, and how to leave it out of an event
+type eventField struct {
+	api   []string
+	clear func(ev *Event)
+}
+
+// eventFields are the fields list_events and get_event can project. The ID,
+// summary, times, status, link, calendar and time zone are always returned.
+var eventFields = map[string]eventField{
+	"description": {
+		api:   []string{"description"},
+		clear: func(ev *Event) { ev.Description = "" },
+	},
+	"location": {
+		api:   []string{"location"},
+		clear: func(ev *Event) { ev.Location = "" },
+	},
+	"attendees": {
+		api:   []string{"attendees"},
+		clear: func(ev *Event) { ev.Attendees = nil },
+	},
+	"organizer": {
+		api:   []string{"organizer"},
+		clear: func(ev *Event) { ev.Organizer = "" },
+	},
+	"response_status": {
+		api:   []string{"attendees(email,self,responseStatus)"},
+		clear: func(ev *Event) { ev.ResponseStatus = "" },
+	},
+	// Meeting links are also extracted from the location and description
+	"conference": {
+		api:   []string{"conferenceData", "hangoutLink", "location", "description"},
+		clear: func(ev *Event) { ev.Conference, ev.MeetingURL, ev.HangoutLink = nil, "", "" },
+	},
+	"transparency": {
+		api:   []string{"transparency"},
+		clear: func(ev *Event) { ev.Transparency = "" },
+	},
+	"ical_uid": {
+		api:   []string{"iCalUID"},
+		clear: func(ev *Event) { ev.ICalUID = "" },
+	},
+	"color": {
+		api:   []string{"colorId"},
+		clear: func(ev *Event) { ev.ColorID, ev.Color = "", nil },
+	},
+	"event_type": {
+		api: []string{"eventType", "workingLocationProperties", "outOfOfficeProperties", "focusTimeProperties"},
+		clear: func(ev *Event) {
+			ev.EventType, ev.WorkingLocation, ev.OutOfOffice, ev.FocusTime = "", nil, nil, nil
+		},
+	},
+	"recurrence": {
+		api:   []string{"recurrence", "recurringEventId", "originalStartTime"},
+		clear: func(ev *Event) { ev.Recurrence, ev.RecurringEventID, ev.OriginalStartTime = nil, "", nil },
+	},
+	"details": {
+		api: []string{
+			"visibility", "reminders", "attachments", "creator", "created", "updated", "sequence", "source",
+			"guestsCanInviteOthers", "guestsCanModify", "guestsCanSeeOtherGuests",
+		},
+		clear: func(ev *Event) { ev.Details = nil },
+	},
+}
+
+// baseAPIFields are the Calendar API fields of every event read
+var baseAPIFields = []string{"id", "summary", "start", "end", "status", "htmlLink"}
+
+// fieldPresets name sets of fields; standard is everything but the details
+var fieldPresets = map[string][]string{
+	"minimal":  {"location", "response_status"},
+	"standard": slices.DeleteFunc(slices.Sorted(maps.Keys(eventFields)), func(f string) bool { return f == "details" }),
+	"full":     slices.Sorted(maps.Keys(eventFields)),
+}
+
+// resolveFields returns the set of fields named by presets and field names,
+// or by the default preset when none is given
+func resolveFields(names []string, defaultPreset string) (map[string]bool, error) {
+	if len(names) == 0 {
+		names = []string{defaultPreset}
+	}
+	fields := make(map[string]bool)
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if preset, ok := fieldPresets[name]; ok {
+			for _, f := range preset {
+				fields[f] = true
+			}
+			continue
+		}
+		if _, ok := eventFields[name]; !ok {
+			return nil, fmt.Errorf("invalid field '%s' (expected minimal, standard, full or one of %s)", name, strings.Join(slices.Sorted(maps.Keys(eventFields)), ", "))
+		}
+		fields[name] = true
+	}
+	return fields, nil
+}
+
+// apiFields returns the Calendar API fields to read for the given fields
+func apiFields(fields map[string]bool) []string {
+	api := slices.Clone(baseAPIFields)
+	for name := range fields {
+		api = append(api, eventFields[name].api...)
+	}
+	// Reading every attendee already covers the user's own response
+	if fields["attendees"] {
+		api = slices.DeleteFunc(api, func(f string) bool { return strings.HasPrefix(f, "attendees(") })
+	}
+	slices.Sort(api)
+	return slices.Compact(api)
+}
+
+// projectEvent leaves out of an event the fields that were not asked for
+func projectEvent(ev *Event, fields map[string]bool) {
+	for name, field := range eventFields {
+		if !fields[name] {
+			field.clear(ev)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestResolveFields verifies presets and field names combine into one set
+func TestResolveFields(t *testing.T) {
+	tests := []struct {
+		names   []string
+		want    []string
+		wantErr bool
+	}{
+		{nil, fieldPresets["standard"], false},
+		{[]string{"minimal"}, []string{"location", "response_status"}, false},
+		{[]string{"minimal", "Attendees"}, []string{"attendees", "location", "response_status"}, false},
+		{[]string{"full"}, fieldPresets["full"], false},
+		{[]string{"everything"}, nil, true},
+	}
+	for _, tt := range tests {
+		fields, err := resolveFields(tt.names, "standard")
+		if (err != nil) != tt.wantErr {
+			t.Errorf("resolveFields(%v) error = %v, wantErr %v", tt.names, err, tt.wantErr)
+			continue
+		}
+		var got []string
+		for name := range fields {
+			got = append(got, name)
+		}
+		slices.Sort(got)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("resolveFields(%v) = %v, want %v", tt.names, got, tt.want)
+		}
+	}
+	if slices.Contains(fieldPresets["standard"], "details") || !slices.Contains(fieldPresets["full"], "details") {
+		t.Error("Expected the details in the full preset only")
+	}
+}
+
+// TestAPIFields verifies the API fields read for a set of fields
+func TestAPIFields(t *testing.T) {
+	got := apiFields(map[string]bool{"response_status": true, "location": true})
+	want := []string{"attendees(email,self,responseStatus)", "end", "htmlLink", "id", "location", "start", "status", "summary"}
+	if !slices.Equal(got, want) {
+		t.Errorf("apiFields() = %v, want %v", got, want)
+	}
+
+	got = apiFields(map[string]bool{"response_status": true, "attendees": true, "conference": true})
+	if slices.Contains(got, "attendees(email,self,responseStatus)") || !slices.Contains(got, "attendees") || !slices.Contains(got, "description") {
+		t.Errorf("Expected every attendee and the description to be read, got %v", got)
+	}
+}
@@ -60,7 +60,7 @@ func main() {
 
 	// Start MCP server
 	ctx := context.Background()
-	server := NewCalendarServer()
+	server := NewCalendarServer(NewGoogleProvider())
 
 	log.Printf("Starting %s v%s", ServerName, ServerVersion)
 	if err := server.Run(ctx, &mcp.StdioTransport{}); err != nil {
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// NextEvent returns the events in progress and the next event to start across
+// the selected calendars of the requested accounts
+func NextEvent(ctx context.Context, provider CalendarProvider, input NextEventInput) (NextEventOutput, error) {
+	return nextEvent(ctx, provider, input, time.Now())
+}
+
+func nextEvent(ctx context.Context, provider CalendarProvider, input NextEventInput, now time.Time) (NextEventOutput, error) {
+	withinDays := input.WithinDays
+	if withinDays <= 0 {
+		withinDays = 7
+	}
+	if withinDays > 31 {
+		withinDays = 31
+	}
+
+	// Events overlapping the window include those already in progress
+	events, err := GetEvents(ctx, provider, ListEventsInput{
+		Account:         input.Account,
+		AllCalendars:    true,
+		TimeMin:         now.Format(time.RFC3339),
+		TimeMax:         now.AddDate(0, 0, withinDays).Format(time.RFC3339),
+		MaxResults:      maxListResults,
+		ExcludeDeclined: !input.IncludeDeclined,
+		TimeZone:        input.TimeZone,
+	})
+	if err != nil {
+		return NextEventOutput{}, err
+	}
+
+	now = now.In(events.TimeMin.Location())
+	output := NextEventOutput{
+		Now:        now,
+		WithinDays: withinDays,
+		TimeZone:   events.TimeZone,
+		Errors:     events.Errors,
+	}
+	for _, ev := range events.Events {
+		// Only events that make the user busy count, declined ones if asked
+		candidate := ev
+		if input.IncludeDeclined {
+			candidate.ResponseStatus = ""
+		}
+		if !blocksTime(candidate, input.IncludeAllDay) {
+			continue
+		}
+		switch {
+		case !ev.Start.After(now) && ev.End.After(now):
+			output.Current = append(output.Current, ev)
+		case ev.Start.After(now) && output.Next == nil:
+			next := ev
+			output.Next = &next
+			output.StartsIn = formatDuration(ev.Start.Sub(now))
+			output.MinutesUntilStart = int(ev.Start.Sub(now).Minutes())
+		}
+	}
+
+	return output, nil
+}
+
+// formatDuration renders a duration in days, hours and minutes, e.g. "1d 2h" or "45m"
+func formatDuration(d time.Duration) string {
+	if d < time.Minute {
+		return "less than a minute"
+	}
+
+	d = d.Truncate(time.Minute)
+	days := int(d / (24 * time.Hour))
+	hours := int(d % (24 * time.Hour) / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+
+	var parts []string
+	if days > 0 {
+		parts = append(parts, fmt.Sprintf("%dd", days))
+	}
+	if hours > 0 {
+		parts = append(parts, fmt.Sprintf("%dh", hours))
+	}
+	if minutes > 0 && days == 0 {
+		parts = append(parts, fmt.Sprintf("%dm", minutes))
+	}
+	return strings.Join(parts, " ")
+}
+
+// dayLabel names the day of t relative to now, both in the same time zone:
+// "today", "tomorrow" or the date
+func dayLabel(t, now time.Time) string {
+	day, today := startOfDay(t), startOfDay(now)
+	switch {
+	case day.Equal(today):
+		return "today"
+	case day.Equal(today.AddDate(0, 0, 1)):
+		return "tomorrow"
+	default:
+		return t.Format("Mon 2006-01-02")
+	}
+}
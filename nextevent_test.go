@@ -0,0 +1,49 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// TestFormatDuration verifies durations are rounded down to whole minutes
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{30 * time.Second, "less than a minute"},
+		{45 * time.Minute, "45m"},
+		{80*time.Minute + 59*time.Second, "1h 20m"},
+		{2 * time.Hour, "2h"},
+		{26*time.Hour + 10*time.Minute, "1d 2h"},
+		{72 * time.Hour, "3d"},
+	}
+	for _, tt := range tests {
+		if got := formatDuration(tt.d); got != tt.want {
+			t.Errorf("formatDuration(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+// TestDayLabel verifies days are named relative to now across a DST change
+func TestDayLabel(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Fatalf("failed to load zone: %v", err)
+	}
+	now := time.Date(2026, 3, 28, 23, 30, 0, 0, paris)
+
+	tests := []struct {
+		t    time.Time
+		want string
+	}{
+		{time.Date(2026, 3, 28, 23, 45, 0, 0, paris), "today"},
+		{time.Date(2026, 3, 29, 9, 0, 0, 0, paris), "tomorrow"},
+		{time.Date(2026, 3, 30, 9, 0, 0, 0, paris), "Mon 2026-03-30"},
+	}
+	for _, tt := range tests {
+		if got := dayLabel(tt.t, now); got != tt.want {
+			t.Errorf("dayLabel(%v) = %q, want %q", tt.t, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,633 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/googleapi"
+)
+
+// CalendarProvider is the calendar backend used by the MCP tools.
+// All methods operate on a single, named account; fanning out across
+// accounts is handled by the callers in calendar.go.
+type CalendarProvider interface {
+	// Accounts returns the names of all configured accounts
+	Accounts() ([]string, error)
+
+	// AccountConfig returns the local configuration of an account
+	AccountConfig(account string) (AccountConfig, error)
+
+	// ListCalendars returns up to query.MaxResults calendars visible to the
+	// account, and the page token continuing the listing when more calendars
+	// were left out
+	ListCalendars(ctx context.Context, account string, query CalendarQuery) ([]Calendar, string, error)
+
+	// ListEvents returns up to query.MaxResults events of one calendar matching
+	// the query, and whether more events were left out
+	ListEvents(ctx context.Context, account string, query EventQuery) ([]Event, bool, error)
+
+	// ListInstances returns the instances of a recurring event in chronological
+	// order, and the page token continuing the listing when more instances
+	// than requested exist
+	ListInstances(ctx context.Context, account string, query InstancesQuery) ([]Event, string, error)
+
+	// GetEvent returns a single event, reading only the given Calendar API
+	// fields if any
+	GetEvent(ctx context.Context, account, calendarID, eventID string, fields []string) (*Event, error)
+
+	// QueryFreeBusy returns the busy periods of the given calendars, along with
+	// the calendars whose free/busy information could not be retrieved
+	QueryFreeBusy(ctx context.Context, account string, calendarIDs []string, timeMin, timeMax time.Time) ([]BusyPeriod, []AccountError, error)
+
+	// Settings returns the Google Calendar settings of the account, keyed by
+	// setting ID, such as timezone or weekStart
+	Settings(ctx context.Context, account string) (map[string]string, error)
+
+	// Colors returns the palettes the color IDs of calendars and events refer to
+	Colors(ctx context.Context, account string) (*ColorPalette, error)
+}
+
+const (
+	// maxPageSize is the largest page the Calendar API returns for list calls
+	maxPageSize = 250
+
+	// settingsTTL bounds how long the settings of an account are reused;
+	// most tool calls read them to find the user's time zone
+	settingsTTL = 5 * time.Minute
+)
+
+// CalendarQuery describes a calendar listing of an account
+type CalendarQuery struct {
+	MaxResults int
+
+	// MinAccessRole leaves out calendars the user has less access to:
+	// freeBusyReader, reader, writer or owner
+	MinAccessRole string
+
+	// ShowHidden and ShowDeleted include the calendars the user hid from,
+	// or removed from, their calendar list
+	ShowHidden  bool
+	ShowDeleted bool
+
+	// SelectedOnly leaves out calendars not shown in the Google Calendar UI;
+	// MaxResults counts the calendars returned
+	SelectedOnly bool
+
+	// PageToken continues a listing made with the same query
+	PageToken string
+}
+
+// EventQuery describes an event listing on a single calendar
+type EventQuery struct {
+	CalendarID string
+	TimeMin    time.Time
+	TimeMax    time.Time
+	MaxResults int
+	Query      string
+
+	// SeriesMasters lists recurring events once, as their series master,
+	// instead of expanding them into instances
+	SeriesMasters bool
+
+	// EventTypes restricts the listing to these event types, if any
+	EventTypes []string
+
+	// Fields restricts the Calendar API fields read of each event, if any
+	Fields []string
+}
+
+// InstancesQuery describes a listing of the instances of a recurring event.
+// Zero time bounds leave the range open.
+type InstancesQuery struct {
+	CalendarID string
+	EventID    string
+	TimeMin    time.Time
+	TimeMax    time.Time
+	MaxResults int
+
+	// PageToken continues a listing made with the same query
+	PageToken string
+}
+
+// GoogleProvider implements CalendarProvider with the Google Calendar API
+type GoogleProvider struct {
+	// loadConfig returns the local account configuration
+	loadConfig func() (*Config, error)
+
+	// newService builds an authenticated Calendar client for an account
+	newService func(ctx context.Context, account string) (*calendar.Service, error)
+
+	// mu guards the settings and color palettes cached per account
+	mu       sync.Mutex
+	settings map[string]cachedSettings
+	palettes map[string]*ColorPalette
+}
+
+// cachedSettings are the settings of an account and when they were read
+type cachedSettings struct {
+	values  map[string]string
+	fetched time.Time
+}
+
+// NewGoogleProvider returns a provider backed by the locally configured accounts
+func NewGoogleProvider() *GoogleProvider {
+	return &GoogleProvider{
+		loadConfig: LoadConfig,
+		newService: GetCalendarService,
+	}
+}
+
+// Accounts returns the configured account names
+func (p *GoogleProvider) Accounts() ([]string, error) {
+	config, err := p.loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	return config.AccountNames(), nil
+}
+
+// AccountConfig returns the configuration of the account, or an empty
+// configuration if the account is not configured
+func (p *GoogleProvider) AccountConfig(account string) (AccountConfig, error) {
+	config, err := p.loadConfig()
+	if err != nil {
+		return AccountConfig{}, err
+	}
+	return config.Accounts[account], nil
+}
+
+// ListCalendars returns the calendar list of the account, following pagination.
+// Pages never hold more calendars than are left to collect, so that the
+// listing stops at the end of a page and its token continues it.
+func (p *GoogleProvider) ListCalendars(ctx context.Context, account string, query CalendarQuery) ([]Calendar, string, error) {
+	srv, err := p.newService(ctx, account)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get service for account '%s': %w", account, err)
+	}
+
+	var calendars []Calendar
+	pageToken := query.PageToken
+	for {
+		call := srv.CalendarList.List().
+			MaxResults(int64(min(query.MaxResults-len(calendars), maxPageSize))).
+			ShowHidden(query.ShowHidden).
+			ShowDeleted(query.ShowDeleted)
+		if query.MinAccessRole != "" {
+			call = call.MinAccessRole(query.MinAccessRole)
+		}
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+
+		list, err := call.Context(ctx).Do()
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to list calendars for account '%s': %w", account, err)
+		}
+
+		for _, item := range list.Items {
+			if query.SelectedOnly && !item.Selected {
+				continue
+			}
+			calendars = append(calendars, Calendar{
+				ID:              item.Id,
+				Summary:         item.Summary,
+				SummaryOverride: item.SummaryOverride,
+				Description:     item.Description,
+				Primary:         item.Primary,
+				Selected:        item.Selected,
+				Hidden:          item.Hidden,
+				Deleted:         item.Deleted,
+				AccessRole:      item.AccessRole,
+				TimeZone:        item.TimeZone,
+				ColorID:         item.ColorId,
+				ColorName:       calendarColorNames[item.ColorId],
+				BackgroundColor: item.BackgroundColor,
+				ForegroundColor: item.ForegroundColor,
+				Account:         account,
+			})
+		}
+
+		if list.NextPageToken == "" {
+			return calendars, "", nil
+		}
+		if len(calendars) >= query.MaxResults {
+			return calendars[:query.MaxResults], list.NextPageToken, nil
+		}
+		pageToken = list.NextPageToken
+	}
+}
+
+// ListEvents returns the expanded events of one calendar, ordered by start time,
+// following pagination until query.MaxResults events have been collected
+func (p *GoogleProvider) ListEvents(ctx context.Context, account string, query EventQuery) ([]Event, bool, error) {
+	srv, err := p.newService(ctx, account)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get service for account '%s': %w", account, err)
+	}
+
+	var events []Event
+	pageToken := ""
+	for {
+		call := srv.Events.List(query.CalendarID).
+			TimeMin(query.TimeMin.Format(time.RFC3339)).
+			TimeMax(query.TimeMax.Format(time.RFC3339)).
+			MaxResults(int64(min(query.MaxResults-len(events), maxPageSize)))
+
+		// Ordering by start time is only supported on expanded instances;
+		// series masters are sorted by the caller
+		if query.SeriesMasters {
+			call = call.SingleEvents(false)
+		} else {
+			call = call.SingleEvents(true).OrderBy("startTime")
+		}
+
+		if query.Query != "" {
+			call = call.Q(query.Query)
+		}
+		if len(query.EventTypes) > 0 {
+			call = call.EventTypes(query.EventTypes...)
+		}
+		if len(query.Fields) > 0 {
+			call = call.Fields(googleapi.Field("nextPageToken,summary,timeZone,items(" + strings.Join(query.Fields, ",") + ")"))
+		}
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+
+		result, err := call.Context(ctx).Do()
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to list events for account '%s': %w", account, err)
+		}
+
+		// The listing's summary and time zone are those of the calendar
+		for _, item := range result.Items {
+			event := parseEvent(item, account, query.CalendarID)
+			event.CalendarName = result.Summary
+			event.TimeZone = cmp.Or(event.TimeZone, result.TimeZone)
+			events = append(events, event)
+		}
+
+		if result.NextPageToken == "" {
+			return events, false, nil
+		}
+		if len(events) >= query.MaxResults {
+			return events[:query.MaxResults], true, nil
+		}
+		pageToken = result.NextPageToken
+	}
+}
+
+// ListInstances returns the instances of a recurring event in chronological
+// order, following pagination in pages no larger than what is left to collect
+func (p *GoogleProvider) ListInstances(ctx context.Context, account string, query InstancesQuery) ([]Event, string, error) {
+	srv, err := p.newService(ctx, account)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get service for account '%s': %w", account, err)
+	}
+
+	var events []Event
+	pageToken := query.PageToken
+	for {
+		call := srv.Events.Instances(query.CalendarID, query.EventID).
+			MaxResults(int64(min(query.MaxResults-len(events), maxPageSize)))
+
+		if !query.TimeMin.IsZero() {
+			call = call.TimeMin(query.TimeMin.Format(time.RFC3339))
+		}
+		if !query.TimeMax.IsZero() {
+			call = call.TimeMax(query.TimeMax.Format(time.RFC3339))
+		}
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+
+		result, err := call.Context(ctx).Do()
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to list instances of event '%s' for account '%s': %w", query.EventID, account, err)
+		}
+
+		for _, item := range result.Items {
+			event := parseEvent(item, account, query.CalendarID)
+			event.CalendarName = result.Summary
+			event.TimeZone = cmp.Or(event.TimeZone, result.TimeZone)
+			events = append(events, event)
+		}
+
+		if result.NextPageToken == "" {
+			return events, "", nil
+		}
+		if len(events) >= query.MaxResults {
+			return events[:query.MaxResults], result.NextPageToken, nil
+		}
+		pageToken = result.NextPageToken
+	}
+}
+
+// GetEvent returns a single event by ID
+func (p *GoogleProvider) GetEvent(ctx context.Context, account, calendarID, eventID string, fields []string) (*Event, error) {
+	srv, err := p.newService(ctx, account)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get service for account '%s': %w", account, err)
+	}
+
+	call := srv.Events.Get(calendarID, eventID)
+	if len(fields) > 0 {
+		call = call.Fields(googleapi.Field(strings.Join(fields, ",")))
+	}
+	item, err := call.Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event: %w", err)
+	}
+
+	event := parseEvent(item, account, calendarID)
+	return &event, nil
+}
+
+// QueryFreeBusy returns the busy periods of the given calendars, along with
+// the calendars whose free/busy information could not be retrieved
+func (p *GoogleProvider) QueryFreeBusy(ctx context.Context, account string, calendarIDs []string, timeMin, timeMax time.Time) ([]BusyPeriod, []AccountError, error) {
+	srv, err := p.newService(ctx, account)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get service for account '%s': %w", account, err)
+	}
+
+	var items []*calendar.FreeBusyRequestItem
+	for _, calID := range calendarIDs {
+		items = append(items, &calendar.FreeBusyRequestItem{Id: calID})
+	}
+
+	req := &calendar.FreeBusyRequest{
+		TimeMin: timeMin.Format(time.RFC3339),
+		TimeMax: timeMax.Format(time.RFC3339),
+		Items:   items,
+	}
+
+	result, err := srv.Freebusy.Query(req).Context(ctx).Do()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query free/busy for account '%s': %w", account, err)
+	}
+
+	// Walk the calendars in request order, the response being a map keyed by
+	// calendar ID, then any calendar the response names differently
+	ids := slices.Clone(calendarIDs)
+	for _, id := range slices.Sorted(maps.Keys(result.Calendars)) {
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+
+	var busyPeriods []BusyPeriod
+	var calendarErrors []AccountError
+	for _, calID := range ids {
+		cal, ok := result.Calendars[calID]
+		if !ok {
+			continue
+		}
+		for _, e := range cal.Errors {
+			reason := cmp.Or(e.Reason, "unknown")
+			calendarErrors = append(calendarErrors, AccountError{
+				Account:    account,
+				CalendarID: calID,
+				Reason:     reason,
+				Error:      fmt.Sprintf("free/busy unavailable: %s", reason),
+			})
+		}
+		for _, busy := range cal.Busy {
+			start, startErr := time.Parse(time.RFC3339, busy.Start)
+			end, endErr := time.Parse(time.RFC3339, busy.End)
+			if err := errors.Join(startErr, endErr); err != nil {
+				calendarErrors = append(calendarErrors, AccountError{
+					Account:    account,
+					CalendarID: calID,
+					Reason:     "invalidBusyPeriod",
+					Error:      fmt.Sprintf("invalid busy period '%s' - '%s': %v", busy.Start, busy.End, err),
+				})
+				continue
+			}
+			busyPeriods = append(busyPeriods, BusyPeriod{
+				Start:      start,
+				End:        end,
+				Account:    account,
+				CalendarID: calID,
+			})
+		}
+	}
+
+	return busyPeriods, calendarErrors, nil
+}
+
+// Settings returns the settings of the account, following pagination. The
+// settings are reused for settingsTTL.
+func (p *GoogleProvider) Settings(ctx context.Context, account string) (map[string]string, error) {
+	p.mu.Lock()
+	cached, ok := p.settings[account]
+	p.mu.Unlock()
+	if ok && time.Since(cached.fetched) < settingsTTL {
+		return maps.Clone(cached.values), nil
+	}
+
+	srv, err := p.newService(ctx, account)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get service for account '%s': %w", account, err)
+	}
+
+	settings := make(map[string]string)
+	pageToken := ""
+	for {
+		call := srv.Settings.List().MaxResults(maxPageSize)
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+
+		list, err := call.Context(ctx).Do()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list settings for account '%s': %w", account, err)
+		}
+		for _, item := range list.Items {
+			settings[item.Id] = item.Value
+		}
+
+		if list.NextPageToken == "" {
+			break
+		}
+		pageToken = list.NextPageToken
+	}
+
+	p.mu.Lock()
+	if p.settings == nil {
+		p.settings = make(map[string]cachedSettings)
+	}
+	p.settings[account] = cachedSettings{values: settings, fetched: time.Now()}
+	p.mu.Unlock()
+	return maps.Clone(settings), nil
+}
+
+// Colors returns the calendar and event color palettes, named after the
+// colors of the Google Calendar UI. The palettes are fixed, so they are read
+// once per account.
+func (p *GoogleProvider) Colors(ctx context.Context, account string) (*ColorPalette, error) {
+	p.mu.Lock()
+	palette, ok := p.palettes[account]
+	p.mu.Unlock()
+	if ok {
+		return palette, nil
+	}
+
+	srv, err := p.newService(ctx, account)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get service for account '%s': %w", account, err)
+	}
+
+	colors, err := srv.Colors.Get().Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get colors for account '%s': %w", account, err)
+	}
+
+	palette = &ColorPalette{
+		Calendar: paletteColors(colors.Calendar, calendarColorNames),
+		Event:    paletteColors(colors.Event, eventColorNames),
+	}
+
+	p.mu.Lock()
+	if p.palettes == nil {
+		p.palettes = make(map[string]*ColorPalette)
+	}
+	p.palettes[account] = palette
+	p.mu.Unlock()
+	return palette, nil
+}
+
+func parseEvent(item *calendar.Event, account, calendarID string) Event {
+	start, allDay := parseEventTime(item.Start)
+	end, _ := parseEventTime(item.End)
+
+	var attendees []Attendee
+	var responseStatus string
+	for _, att := range item.Attendees {
+		attendees = append(attendees, Attendee{
+			Email:            att.Email,
+			DisplayName:      att.DisplayName,
+			ResponseStatus:   att.ResponseStatus,
+			Optional:         att.Optional,
+			Organizer:        att.Organizer,
+			Self:             att.Self,
+			Resource:         att.Resource,
+			AdditionalGuests: int(att.AdditionalGuests),
+			Comment:          att.Comment,
+		})
+		if att.Self {
+			responseStatus = att.ResponseStatus
+		}
+	}
+
+	var organizer string
+	if item.Organizer != nil {
+		organizer = item.Organizer.Email
+	}
+
+	var timeZone string
+	if item.Start != nil {
+		timeZone = item.Start.TimeZone
+	}
+
+	conference := parseConference(item)
+	outOfOffice, focusTime := parseStatusProperties(item)
+
+	var originalStart *time.Time
+	if t, _ := parseEventTime(item.OriginalStartTime); !t.IsZero() {
+		originalStart = &t
+	}
+
+	return Event{
+		ID:                item.Id,
+		Summary:           item.Summary,
+		Description:       item.Description,
+		Location:          item.Location,
+		Start:             start,
+		End:               end,
+		AllDay:            allDay,
+		Attendees:         attendees,
+		Organizer:         organizer,
+		ResponseStatus:    responseStatus,
+		HangoutLink:       item.HangoutLink,
+		Conference:        conference,
+		MeetingURL:        meetingURL(conference),
+		Status:            item.Status,
+		Transparency:      item.Transparency,
+		ColorID:           item.ColorId,
+		Color:             namedColor(item.ColorId, eventColorNames),
+		EventType:         item.EventType,
+		WorkingLocation:   parseWorkingLocation(item),
+		OutOfOffice:       outOfOffice,
+		FocusTime:         focusTime,
+		ICalUID:           item.ICalUID,
+		Details:           parseEventDetails(item),
+		HtmlLink:          item.HtmlLink,
+		Account:           account,
+		CalendarID:        calendarID,
+		TimeZone:          timeZone,
+		Recurrence:        item.Recurrence,
+		RecurringEventID:  item.RecurringEventId,
+		OriginalStartTime: originalStart,
+	}
+}
+
+// parseEventDetails reads the less used properties of an event, applying the
+// API defaults of the guest permissions
+func parseEventDetails(item *calendar.Event) *EventDetails {
+	details := &EventDetails{
+		Visibility:              item.Visibility,
+		Sequence:                int(item.Sequence),
+		GuestsCanInviteOthers:   item.GuestsCanInviteOthers == nil || *item.GuestsCanInviteOthers,
+		GuestsCanModify:         item.GuestsCanModify,
+		GuestsCanSeeOtherGuests: item.GuestsCanSeeOtherGuests == nil || *item.GuestsCanSeeOtherGuests,
+	}
+	details.Created, _ = time.Parse(time.RFC3339, item.Created)
+	details.Updated, _ = time.Parse(time.RFC3339, item.Updated)
+	if item.Creator != nil {
+		details.Creator = item.Creator.Email
+	}
+	if item.Source != nil {
+		details.Source = &EventSource{Title: item.Source.Title, URL: item.Source.Url}
+	}
+	if item.Reminders != nil {
+		details.Reminders = &Reminders{UseDefault: item.Reminders.UseDefault}
+		for _, r := range item.Reminders.Overrides {
+			details.Reminders.Overrides = append(details.Reminders.Overrides, Reminder{Method: r.Method, Minutes: int(r.Minutes)})
+		}
+	}
+	for _, a := range item.Attachments {
+		details.Attachments = append(details.Attachments, Attachment{
+			Title:    a.Title,
+			FileURL:  a.FileUrl,
+			MimeType: a.MimeType,
+			FileID:   a.FileId,
+		})
+	}
+	return details
+}
+
+// parseEventTime parses an event date-time, reporting whether it is a date
+// without time, as used by all-day events
+func parseEventTime(dt *calendar.EventDateTime) (time.Time, bool) {
+	if dt == nil {
+		return time.Time{}, false
+	}
+	if dt.DateTime != "" {
+		t, _ := time.Parse(time.RFC3339, dt.DateTime)
+		return t, false
+	}
+	if dt.Date != "" {
+		t, _ := time.Parse("2006-01-02", dt.Date)
+		return t, true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,1846 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// TestGoogleProviderListCalendars lists calendars of every account through MCP
+func TestGoogleProviderListCalendars(t *testing.T) {
+	provider := newFakeGoogleProvider(t, seededFakeAccounts())
+
+	res := callTool(t, provider, "list_calendars", map[string]any{})
+	var out ListCalendarsOutput
+	structuredOutput(t, res, &out)
+
+	if len(out.Calendars) != 5 {
+		t.Fatalf("Expected 5 calendars, got %d: %+v", len(out.Calendars), out.Calendars)
+	}
+	if text := resultText(t, res); !strings.Contains(text, "[work] Team") {
+		t.Errorf("Expected team calendar in text, got %q", text)
+	}
+}
+
+// TestGoogleProviderCalendarMetadata checks calendar list metadata and filters
+func TestGoogleProviderCalendarMetadata(t *testing.T) {
+	accounts := seededFakeAccounts()
+	work := accounts["work"]
+	work.calendars[1].SummaryOverride = "My team"
+	work.calendars = append(work.calendars, &calendar.CalendarListEntry{
+		Id: "boss@work.example.com", Summary: "boss@work.example.com", AccessRole: "freeBusyReader", TimeZone: "America/New_York", Hidden: true,
+	})
+	provider := newFakeGoogleProvider(t, accounts)
+
+	res := callTool(t, provider, "list_calendars", map[string]any{"account": "work"})
+	var out ListCalendarsOutput
+	structuredOutput(t, res, &out)
+	if len(out.Calendars) != 3 || out.Calendars[1].AccessRole != "writer" || out.Calendars[1].Name() != "My team" {
+		t.Fatalf("Expected the visible work calendars with metadata, got %+v", out.Calendars)
+	}
+	if text := resultText(t, res); !strings.Contains(text, "- [work] My team - writer, America/New_York") {
+		t.Errorf("Expected the calendar's own name for the user in %q", text)
+	}
+
+	tests := []struct {
+		name string
+		args map[string]any
+		want []string
+	}{
+		{"selected", map[string]any{"selected_only": true}, []string{"me@work.example.com", "team@group.calendar.google.com"}},
+		{"writer", map[string]any{"min_access_role": "writer"}, []string{"me@work.example.com", "team@group.calendar.google.com"}},
+		{"hidden", map[string]any{"include_hidden": true, "min_access_role": "freeBusyReader"}, []string{"me@work.example.com", "team@group.calendar.google.com", "oncall@group.calendar.google.com", "boss@work.example.com"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := map[string]any{"account": "work"}
+			maps.Copy(args, tt.args)
+			res := callTool(t, provider, "list_calendars", args)
+			var out ListCalendarsOutput
+			structuredOutput(t, res, &out)
+			var ids []string
+			for _, cal := range out.Calendars {
+				ids = append(ids, cal.ID)
+			}
+			if !slices.Equal(ids, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, ids)
+			}
+		})
+	}
+
+	res = callTool(t, provider, "list_calendars", map[string]any{"account": "work", "include_hidden": true})
+	if text := resultText(t, res); !strings.Contains(text, "boss@work.example.com - freeBusyReader, America/New_York, hidden (free/busy only - events cannot be listed)") {
+		t.Errorf("Expected the free/busy calendar to be flagged in %q", text)
+	}
+	res = callTool(t, provider, "list_calendars", map[string]any{"min_access_role": "admin"})
+	if !res.IsError || !strings.Contains(resultText(t, res), "invalid min_access_role 'admin'") {
+		t.Errorf("Expected an invalid role error, got %q", resultText(t, res))
+	}
+
+	// Events are tagged with the name the user gave their calendar
+	res = callTool(t, provider, "list_events", map[string]any{
+		"account":       "work",
+		"all_calendars": true,
+		"time_min":      "2026-02-04T00:00:00-05:00",
+		"time_max":      "2026-02-05T00:00:00-05:00",
+	})
+	var events ListEventsOutput
+	structuredOutput(t, res, &events)
+	if len(events.Events) != 1 || events.Events[0].CalendarName != "My team" {
+		t.Errorf("Expected the offsite on My team, got %+v", events.Events)
+	}
+	res = callTool(t, provider, "list_events", map[string]any{
+		"account":     "work",
+		"calendar_id": "team@group.calendar.google.com",
+		"time_min":    "2026-02-04T00:00:00-05:00",
+		"time_max":    "2026-02-05T00:00:00-05:00",
+	})
+	structuredOutput(t, res, &events)
+	if len(events.Events) != 1 || events.Events[0].CalendarName != "My team" {
+		t.Errorf("Expected the offsite on My team when the calendar is given by ID, got %+v", events.Events)
+	}
+
+	// max_results counts the calendars of all accounts, in account order
+	res = callTool(t, provider, "list_calendars", map[string]any{"max_results": 3})
+	structuredOutput(t, res, &out)
+	if len(out.Calendars) != 3 || out.Calendars[2].ID != "me@work.example.com" || !out.Truncated {
+		t.Errorf("Expected the personal calendars then the first work one, got %+v", out.Calendars)
+	}
+
+	// Selected calendars fill max_results, skipping the unselected ones
+	work.mu.Lock()
+	work.calendars[1].Selected, work.calendars[2].Selected = false, true
+	work.mu.Unlock()
+	res = callTool(t, provider, "list_calendars", map[string]any{"account": "work", "max_results": 2, "selected_only": true})
+	structuredOutput(t, res, &out)
+	var selected []string
+	for _, cal := range out.Calendars {
+		selected = append(selected, cal.ID)
+	}
+	if want := []string{"me@work.example.com", "oncall@group.calendar.google.com"}; !slices.Equal(selected, want) {
+		t.Errorf("Expected %v, got %v", want, selected)
+	}
+}
+
+// TestGoogleProviderListEvents runs list_events end to end against the fake API
+func TestGoogleProviderListEvents(t *testing.T) {
+	accounts := seededFakeAccounts()
+	provider := newFakeGoogleProvider(t, accounts)
+
+	res := callTool(t, provider, "list_events", map[string]any{
+		"account":  "personal",
+		"time_min": "2026-02-02T00:00:00Z",
+		"time_max": "2026-02-09T00:00:00Z",
+	})
+	var out ListEventsOutput
+	structuredOutput(t, res, &out)
+
+	if len(out.Events) != 2 {
+		t.Fatalf("Expected 2 events, got %d: %+v", len(out.Events), out.Events)
+	}
+
+	dentist := out.Events[0]
+	if dentist.ID != "dentist" || dentist.AllDay {
+		t.Errorf("Expected timed dentist event first, got %+v", dentist)
+	}
+	if want := time.Date(2026, 2, 3, 8, 0, 0, 0, time.UTC); !dentist.Start.Equal(want) {
+		t.Errorf("Expected dentist start %v, got %v", want, dentist.Start)
+	}
+	if dentist.Account != "personal" || dentist.CalendarID != "primary" {
+		t.Errorf("Expected personal/primary, got %s/%s", dentist.Account, dentist.CalendarID)
+	}
+
+	log := eventRequests(accounts["personal"])
+	if len(log) != 1 || !strings.Contains(log[0], "singleEvents=true") || !strings.Contains(log[0], "orderBy=startTime") {
+		t.Errorf("Unexpected requests: %v", log)
+	}
+}
+
+// TestGoogleProviderAllDayEvent verifies all-day dates are parsed as all-day events
+func TestGoogleProviderAllDayEvent(t *testing.T) {
+	provider := newFakeGoogleProvider(t, seededFakeAccounts())
+
+	res := callTool(t, provider, "get_event", map[string]any{
+		"account":     "personal",
+		"calendar_id": "primary",
+		"event_id":    "ski-trip",
+	})
+	var out GetEventOutput
+	structuredOutput(t, res, &out)
+
+	if !out.Event.AllDay {
+		t.Errorf("Expected all-day event, got %+v", out.Event)
+	}
+	if out.Event.Start.Format("2006-01-02") != "2026-02-06" || out.Event.End.Format("2006-01-02") != "2026-02-09" {
+		t.Errorf("Unexpected all-day range %v - %v", out.Event.Start, out.Event.End)
+	}
+}
+
+// TestGoogleProviderSearch verifies the query is forwarded to the API
+func TestGoogleProviderSearch(t *testing.T) {
+	provider := newFakeGoogleProvider(t, seededFakeAccounts())
+
+	output, err := GetEvents(context.Background(), provider, ListEventsInput{
+		Account: "work",
+		TimeMin: "2026-02-02T00:00:00Z",
+		Query:   "daily sync",
+	})
+	if err != nil {
+		t.Fatalf("GetEvents() error: %v", err)
+	}
+	if len(output.Events) != 1 || output.Events[0].ID != "standup-20260202" {
+		t.Errorf("Expected only the standup, got %+v", output.Events)
+	}
+}
+
+// TestGoogleProviderRecurringEvents verifies series linkage on instances and the
+// listing of series masters without expansion
+func TestGoogleProviderRecurringEvents(t *testing.T) {
+	provider := newFakeGoogleProvider(t, seededFakeAccounts())
+	args := map[string]any{
+		"account":  "work",
+		"time_min": "2026-02-02T00:00:00Z",
+		"time_max": "2026-02-09T00:00:00Z",
+	}
+
+	var expanded ListEventsOutput
+	structuredOutput(t, callTool(t, provider, "list_events", args), &expanded)
+	if len(expanded.Events) != 2 || expanded.Events[0].ID != "standup-20260202" {
+		t.Fatalf("Expected the standup instance and the review, got %+v", expanded.Events)
+	}
+	instance := expanded.Events[0]
+	if instance.RecurringEventID != "standup" || instance.OriginalStartTime == nil || !instance.OriginalStartTime.Equal(instance.Start) {
+		t.Errorf("Expected series linkage on the instance, got %+v", instance)
+	}
+
+	args["series_masters"] = true
+	res := callTool(t, provider, "list_events", args)
+	var masters ListEventsOutput
+	structuredOutput(t, res, &masters)
+	if len(masters.Events) != 2 || masters.Events[0].ID != "standup" {
+		t.Fatalf("Expected the standup series and the review, got %+v", masters.Events)
+	}
+	if want := []string{"RRULE:FREQ=WEEKLY;BYDAY=MO;COUNT=4"}; !slices.Equal(masters.Events[0].Recurrence, want) {
+		t.Errorf("Expected recurrence %v, got %v", want, masters.Events[0].Recurrence)
+	}
+	if text := resultText(t, res); !strings.Contains(text, "Standup [repeats: RRULE:FREQ=WEEKLY;BYDAY=MO;COUNT=4]") {
+		t.Errorf("Unexpected text %q", text)
+	}
+}
+
+// TestGoogleProviderEventInstances lists the instances of a series from one of its instances
+func TestGoogleProviderEventInstances(t *testing.T) {
+	provider := newFakeGoogleProvider(t, seededFakeAccounts())
+
+	res := callTool(t, provider, "list_event_instances", map[string]any{
+		"account":     "work",
+		"calendar_id": "primary",
+		"event_id":    "standup-20260209",
+		"time_min":    "2026-02-01T00:00:00Z",
+		"max_results": 3,
+	})
+	var out ListEventInstancesOutput
+	structuredOutput(t, res, &out)
+
+	if out.Series.ID != "standup" || len(out.Series.Recurrence) != 1 {
+		t.Errorf("Expected the standup series, got %+v", out.Series)
+	}
+	var ids []string
+	for _, ev := range out.Instances {
+		ids = append(ids, ev.ID)
+	}
+	if want := []string{"standup-20260202", "standup-20260209", "standup-20260216"}; !slices.Equal(ids, want) || !out.Truncated {
+		t.Errorf("Expected truncated instances %v, got %v (truncated %v)", want, ids, out.Truncated)
+	}
+	text := resultText(t, res)
+	if !strings.Contains(text, "- 2026-02-17 09:30: Standup (moved from 2026-02-16 09:30)") || !strings.Contains(text, "more instances follow") {
+		t.Errorf("Unexpected text %q", text)
+	}
+
+	// The cursor continues after the last instance returned
+	res = callTool(t, provider, "list_event_instances", map[string]any{
+		"account":     "work",
+		"calendar_id": "primary",
+		"event_id":    "standup-20260209",
+		"time_min":    "2026-02-01T00:00:00Z",
+		"max_results": 3,
+		"cursor":      out.NextCursor,
+	})
+	var next ListEventInstancesOutput
+	structuredOutput(t, res, &next)
+	if len(next.Instances) != 1 || next.Instances[0].ID != "standup-20260223" || next.Truncated || next.NextCursor != "" {
+		t.Errorf("Expected the last instance only, got %+v", next)
+	}
+
+	res = callTool(t, provider, "list_event_instances", map[string]any{
+		"account":     "work",
+		"calendar_id": "primary",
+		"event_id":    "review-20260203",
+	})
+	if !res.IsError || !strings.Contains(resultText(t, res), "'review-20260203' is not a recurring event") {
+		t.Errorf("Expected a not recurring error, got %+v", res)
+	}
+}
+
+// TestGoogleProviderTimeZones verifies events are shown in the user's time zone,
+// all-day events keep their date and the original time zone is preserved
+func TestGoogleProviderTimeZones(t *testing.T) {
+	provider := newFakeGoogleProvider(t, seededFakeAccounts())
+	args := map[string]any{
+		"time_min": "2026-02-02T00:00:00Z",
+		"time_max": "2026-02-07T00:00:00Z",
+	}
+
+	// Without an override, times are shown in the zone of the first account
+	res := callTool(t, provider, "list_events", args)
+	var out ListEventsOutput
+	structuredOutput(t, res, &out)
+	if out.TimeZone != "Europe/Paris" {
+		t.Errorf("Expected Europe/Paris, got %q", out.TimeZone)
+	}
+	text := resultText(t, res)
+	if !strings.Contains(text, "[work] 2026-02-02 15:30: Standup") || !strings.Contains(text, "[personal] 2026-02-06 (all day): Ski trip") {
+		t.Errorf("Unexpected text %q", text)
+	}
+
+	args["time_zone"] = "America/Los_Angeles"
+	res = callTool(t, provider, "list_events", args)
+	structuredOutput(t, res, &out)
+	text = resultText(t, res)
+	if !strings.Contains(text, "[personal] 2026-02-03 00:00: Dentist") || !strings.Contains(text, "[personal] 2026-02-06 (all day): Ski trip") {
+		t.Errorf("Unexpected text %q", text)
+	}
+	for _, ev := range out.Events {
+		if ev.ID == "dentist" && (ev.TimeZone != "Europe/Paris" || ev.Start.Format("15:04-07:00") != "00:00-08:00") {
+			t.Errorf("Expected the dentist in Los Angeles time from Paris, got %+v", ev)
+		}
+		if ev.ID == "ski-trip" && ev.Start.Format(time.RFC3339) != "2026-02-06T00:00:00-08:00" {
+			t.Errorf("Expected the ski trip anchored on Feb 6, got %v", ev.Start)
+		}
+	}
+
+	res = callTool(t, provider, "get_event", map[string]any{"account": "work", "calendar_id": "primary", "event_id": "review-20260203"})
+	if text := resultText(t, res); !strings.Contains(text, "When: 2026-02-03 03:00 - 2026-02-03 04:00") {
+		t.Errorf("Expected the review in the work account's time zone, got %q", text)
+	}
+
+	res = callTool(t, provider, "get_event", map[string]any{"account": "work", "calendar_id": "primary", "event_id": "review-20260203", "time_zone": "Europe/Paris"})
+	if text := resultText(t, res); !strings.Contains(text, "When: 2026-02-03 09:00 - 2026-02-03 10:00 (Europe/Paris)") || !strings.Contains(text, "Scheduled in: America/New_York") {
+		t.Errorf("Expected the review in Paris time, got %q", text)
+	}
+}
+
+// TestGoogleProviderTimeExpressions verifies relative time ranges are resolved
+// in the user's time zone and echoed back
+func TestGoogleProviderTimeExpressions(t *testing.T) {
+	provider := newFakeGoogleProvider(t, seededFakeAccounts())
+
+	res := callTool(t, provider, "list_events", map[string]any{
+		"account":  "personal",
+		"time_min": "2026-02-03",
+		"time_max": "2026-W06",
+	})
+	var out ListEventsOutput
+	structuredOutput(t, res, &out)
+
+	if got := out.TimeMin.Format(time.RFC3339); got != "2026-02-03T00:00:00+01:00" {
+		t.Errorf("Expected time_min at midnight in Paris, got %s", got)
+	}
+	if got := out.TimeMax.Format(time.RFC3339); got != "2026-02-09T00:00:00+01:00" {
+		t.Errorf("Expected time_max at the end of week 6, got %s", got)
+	}
+	if len(out.Events) != 2 {
+		t.Errorf("Expected the dentist and the ski trip, got %+v", out.Events)
+	}
+	if text := resultText(t, res); !strings.Contains(text, "Found 2 event(s) between 2026-02-03 00:00 and 2026-02-09 00:00 (Europe/Paris)") {
+		t.Errorf("Unexpected text %q", text)
+	}
+
+	res = callTool(t, provider, "check_availability", map[string]any{
+		"account":  "work",
+		"time_min": "2026-02-03",
+		"time_max": "+6h",
+	})
+	var busy CheckAvailabilityOutput
+	structuredOutput(t, res, &busy)
+	if len(busy.BusyPeriods) != 1 || !busy.TimeMax.Equal(time.Date(2026, 2, 3, 11, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the design review until 06:00 New York time, got %+v", busy)
+	}
+}
+
+// TestGoogleProviderAttendees verifies structured attendees, the user's own
+// response and the response filters of list_events
+func TestGoogleProviderAttendees(t *testing.T) {
+	provider := newFakeGoogleProvider(t, seededFakeAccounts())
+
+	res := callTool(t, provider, "get_event", map[string]any{"account": "work", "calendar_id": "primary", "event_id": "review-20260203"})
+	var event GetEventOutput
+	structuredOutput(t, res, &event)
+
+	if event.Event.ResponseStatus != "needsAction" || len(event.Event.Attendees) != 5 {
+		t.Fatalf("Expected 5 attendees and an unanswered invitation, got %+v", event.Event)
+	}
+	want := Attendee{Email: "alice@work.example.com", DisplayName: "Alice Martin", ResponseStatus: "tentative", Optional: true, AdditionalGuests: 1}
+	if event.Event.Attendees[2] != want {
+		t.Errorf("Expected %+v, got %+v", want, event.Event.Attendees[2])
+	}
+	text := resultText(t, res)
+	for _, line := range []string{
+		"Your response: not answered",
+		"- Alice Martin <alice@work.example.com> (maybe, optional, +1 guest(s))",
+		"- Room 4 <room-4@resource.calendar.google.com> (accepted, resource)",
+		"- lead@work.example.com (accepted, organizer)",
+	} {
+		if !strings.Contains(text, line) {
+			t.Errorf("Expected %q in %q", line, text)
+		}
+	}
+
+	args := map[string]any{
+		"account":       "work",
+		"all_calendars": true,
+		"time_min":      "2026-02-02T00:00:00Z",
+		"time_max":      "2026-02-09T00:00:00Z",
+	}
+	ids := func(args map[string]any) []string {
+		var out ListEventsOutput
+		structuredOutput(t, callTool(t, provider, "list_events", args), &out)
+		var ids []string
+		for _, ev := range out.Events {
+			ids = append(ids, ev.ID)
+		}
+		return ids
+	}
+
+	args["exclude_declined"] = true
+	if got, want := ids(args), []string{"standup-20260202", "review-20260203"}; !slices.Equal(got, want) {
+		t.Errorf("Expected %v without declined events, got %v", want, got)
+	}
+	delete(args, "exclude_declined")
+	args["accepted_only"] = true
+	if got, want := ids(args), []string{"standup-20260202"}; !slices.Equal(got, want) {
+		t.Errorf("Expected %v with accepted events only, got %v", want, got)
+	}
+	delete(args, "accepted_only")
+	if text := resultText(t, callTool(t, provider, "list_events", args)); !strings.Contains(text, "Team offsite (Team) (you: declined)") {
+		t.Errorf("Expected the declined offsite to be marked, got %q", text)
+	}
+}
+
+// TestGoogleProviderConference verifies conference data and meeting links found in descriptions
+func TestGoogleProviderConference(t *testing.T) {
+	provider := newFakeGoogleProvider(t, seededFakeAccounts())
+
+	res := callTool(t, provider, "get_event", map[string]any{"account": "work", "calendar_id": "primary", "event_id": "standup-20260202"})
+	var standup GetEventOutput
+	structuredOutput(t, res, &standup)
+	if standup.Event.MeetingURL != "https://meet.google.com/abc-defg-hij" || standup.Event.HangoutLink != standup.Event.MeetingURL {
+		t.Errorf("Expected the Meet link, got %+v", standup.Event)
+	}
+	text := resultText(t, res)
+	for _, line := range []string{
+		"Join (Google Meet):",
+		"- phone: tel:+1-555-0100 (+1 555-0100) PIN 123456789",
+		"- sip: sip:123456789@gmeet.redial.example.com PIN 123456789",
+	} {
+		if !strings.Contains(text, line) {
+			t.Errorf("Expected %q in %q", line, text)
+		}
+	}
+
+	res = callTool(t, provider, "get_event", map[string]any{"account": "work", "calendar_id": "primary", "event_id": "review-20260203"})
+	var review GetEventOutput
+	structuredOutput(t, res, &review)
+	if conf := review.Event.Conference; conf == nil || conf.Source != "extracted" || conf.Solution != "Zoom" {
+		t.Fatalf("Expected a Zoom link extracted from the description, got %+v", review.Event.Conference)
+	}
+	if review.Event.MeetingURL != "https://us02web.zoom.us/j/8123456789?pwd=xyz&from=addon" {
+		t.Errorf("Unexpected meeting URL %q", review.Event.MeetingURL)
+	}
+}
+
+// TestGoogleProviderCheckAvailability verifies free/busy ignores transparent events
+func TestGoogleProviderCheckAvailability(t *testing.T) {
+	provider := newFakeGoogleProvider(t, seededFakeAccounts())
+
+	res := callTool(t, provider, "check_availability", map[string]any{
+		"account":  "personal",
+		"time_min": "2026-02-02T00:00:00Z",
+		"time_max": "2026-02-09T00:00:00Z",
+	})
+	var out CheckAvailabilityOutput
+	structuredOutput(t, res, &out)
+
+	if len(out.BusyPeriods) != 1 {
+		t.Fatalf("Expected 1 busy period, got %+v", out.BusyPeriods)
+	}
+	if want := time.Date(2026, 2, 3, 8, 0, 0, 0, time.UTC); !out.BusyPeriods[0].Start.Equal(want) {
+		t.Errorf("Expected busy period at %v, got %v", want, out.BusyPeriods[0].Start)
+	}
+}
+
+// TestGoogleProviderCheckAvailabilityMerged verifies the merged timeline across
+// accounts and the reporting of calendars without free/busy information
+func TestGoogleProviderCheckAvailabilityMerged(t *testing.T) {
+	provider := newFakeGoogleProvider(t, seededFakeAccounts())
+
+	res := callTool(t, provider, "check_availability", map[string]any{
+		"calendars": []string{"primary", "ghost@group.calendar.google.com"},
+		"time_min":  "2026-02-03T00:00:00Z",
+		"time_max":  "2026-02-04T00:00:00Z",
+		"merge":     true,
+	})
+	var out CheckAvailabilityOutput
+	structuredOutput(t, res, &out)
+
+	// The dentist (08:00-08:45Z) and the design review (08:00-09:00Z) overlap
+	if len(out.BusyPeriods) != 2 || len(out.BusyBlocks) != 1 {
+		t.Fatalf("Expected 2 periods merged into 1 block, got %+v", out)
+	}
+	block := out.BusyBlocks[0]
+	if !block.Start.Equal(time.Date(2026, 2, 3, 8, 0, 0, 0, time.UTC)) || !block.End.Equal(time.Date(2026, 2, 3, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected block bounds %+v", block)
+	}
+	want := []BusySource{{Account: "personal", CalendarID: "primary"}, {Account: "work", CalendarID: "primary"}}
+	if !slices.Equal(block.Sources, want) {
+		t.Errorf("Expected sources %+v, got %+v", want, block.Sources)
+	}
+
+	if len(out.Errors) != 2 {
+		t.Fatalf("Expected the unknown calendar reported for both accounts, got %+v", out.Errors)
+	}
+	for _, e := range out.Errors {
+		if e.CalendarID != "ghost@group.calendar.google.com" || e.Reason != "notFound" {
+			t.Errorf("Unexpected calendar error %+v", e)
+		}
+	}
+
+	text := resultText(t, res)
+	if !strings.Contains(text, "Found 1 busy block(s) from 2 busy period(s)") ||
+		!strings.Contains(text, "(personal: primary, work: primary)") || strings.Contains(text, "you're free") {
+		t.Errorf("Unexpected text %q", text)
+	}
+}
+
+// TestGoogleProviderCheckAvailabilityCalendarErrors verifies calendars that could
+// not be checked are reported and never read as free time
+func TestGoogleProviderCheckAvailabilityCalendarErrors(t *testing.T) {
+	accounts := seededFakeAccounts()
+	accounts["work"].freeBusy = map[string]calendar.FreeBusyCalendar{
+		"primary": {Errors: []*calendar.Error{{Domain: "global", Reason: "internalError"}}},
+	}
+	accounts["personal"].freeBusy = map[string]calendar.FreeBusyCalendar{
+		"primary": {Busy: []*calendar.TimePeriod{{Start: "2026-02-02T10:00:00Z", End: "tomorrow"}}},
+	}
+	provider := newFakeGoogleProvider(t, accounts)
+
+	res := callTool(t, provider, "check_availability", map[string]any{
+		"time_min": "2026-02-02T00:00:00Z",
+		"time_max": "2026-02-03T00:00:00Z",
+	})
+	var out CheckAvailabilityOutput
+	structuredOutput(t, res, &out)
+
+	if len(out.BusyPeriods) != 0 {
+		t.Errorf("Expected the malformed period to be dropped, got %+v", out.BusyPeriods)
+	}
+	reasons := map[string]string{}
+	for _, e := range out.Errors {
+		reasons[e.Account+"/"+e.CalendarID] = e.Reason
+	}
+	want := map[string]string{"personal/primary": "invalidBusyPeriod", "work/primary": "internalError"}
+	if !maps.Equal(reasons, want) {
+		t.Errorf("Expected calendar errors %v, got %+v", want, out.Errors)
+	}
+
+	text := resultText(t, res)
+	if strings.Contains(text, "you're free") || !strings.Contains(text, "availability is unknown") ||
+		!strings.Contains(text, "Could not query 2 calendar(s):") || !strings.Contains(text, "- [work] primary: free/busy unavailable: internalError") {
+		t.Errorf("Unexpected text %q", text)
+	}
+}
+
+// TestGoogleProviderErrors verifies API and account errors surface as tool errors
+func TestGoogleProviderErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		tool     string
+		args     map[string]any
+		setup    func(map[string]*fakeCalendarAPI)
+		contains string
+	}{
+		{
+			name:     "event not found",
+			tool:     "get_event",
+			args:     map[string]any{"account": "work", "calendar_id": "primary", "event_id": "missing"},
+			contains: "404",
+		},
+		{
+			name:     "unknown calendar",
+			tool:     "list_events",
+			args:     map[string]any{"account": "work", "calendar_id": "nope@example.com"},
+			contains: "404",
+		},
+		{
+			name:     "unknown account",
+			tool:     "list_calendars",
+			args:     map[string]any{"account": "nobody"},
+			contains: "failed to load token for account 'nobody'",
+		},
+		{
+			name:     "expired credentials",
+			tool:     "list_events",
+			args:     map[string]any{"account": "work"},
+			setup:    func(a map[string]*fakeCalendarAPI) { a["work"].failWith = 401 },
+			contains: "401",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accounts := seededFakeAccounts()
+			if tt.setup != nil {
+				tt.setup(accounts)
+			}
+			provider := newFakeGoogleProvider(t, accounts)
+
+			res := callTool(t, provider, tt.tool, tt.args)
+			if !res.IsError {
+				t.Fatalf("Expected tool error, got %+v", res.StructuredContent)
+			}
+			if text := resultText(t, res); !strings.Contains(text, tt.contains) {
+				t.Errorf("Expected error containing %q, got %q", tt.contains, text)
+			}
+		})
+	}
+}
+
+// TestGoogleProviderEventPagination verifies pages are followed up to max_results
+func TestGoogleProviderEventPagination(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxResults int
+		want       int
+		truncated  bool
+	}{
+		{name: "all pages", maxResults: 50, want: 7, truncated: false},
+		{name: "cap across pages", maxResults: 5, want: 5, truncated: true},
+		{name: "cap on page boundary", maxResults: 4, want: 4, truncated: true},
+		{name: "cap equals total", maxResults: 7, want: 7, truncated: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeCalendarAPI(&calendar.CalendarListEntry{Id: "me@example.com", Primary: true})
+			api.pageSize = 2
+			for i := range 7 {
+				start := time.Date(2026, 2, 2, 8+i, 0, 0, 0, time.UTC)
+				api.addEvents("me@example.com", &calendar.Event{
+					Id:      fmt.Sprintf("ev%d", i),
+					Summary: fmt.Sprintf("Event %d", i),
+					Start:   &calendar.EventDateTime{DateTime: start.Format(time.RFC3339)},
+					End:     &calendar.EventDateTime{DateTime: start.Add(30 * time.Minute).Format(time.RFC3339)},
+				})
+			}
+			provider := newFakeGoogleProvider(t, map[string]*fakeCalendarAPI{"personal": api})
+
+			res := callTool(t, provider, "list_events", map[string]any{
+				"time_min":    "2026-02-02T00:00:00Z",
+				"max_results": tt.maxResults,
+			})
+			var out ListEventsOutput
+			structuredOutput(t, res, &out)
+
+			if len(out.Events) != tt.want || out.Truncated != tt.truncated {
+				t.Errorf("Expected %d events (truncated=%v), got %d (truncated=%v)", tt.want, tt.truncated, len(out.Events), out.Truncated)
+			}
+			for i, ev := range out.Events {
+				if ev.ID != fmt.Sprintf("ev%d", i) {
+					t.Errorf("Expected ev%d at position %d, got %s", i, i, ev.ID)
+				}
+			}
+		})
+	}
+}
+
+// TestGoogleProviderEventCursor follows list_events cursors across accounts
+// and calendars, expecting the same events as a single listing
+func TestGoogleProviderEventCursor(t *testing.T) {
+	accounts := seededFakeAccounts()
+	accounts["work"].pageSize = 1
+	provider := newFakeGoogleProvider(t, accounts)
+	args := map[string]any{
+		"all_calendars": true,
+		"time_min":      "2026-02-01T00:00:00Z",
+		"time_max":      "2026-03-01T00:00:00Z",
+	}
+
+	// Declined events are filtered after paging, leaving some pages short
+	for _, excludeDeclined := range []bool{false, true} {
+		args["exclude_declined"] = excludeDeclined
+		delete(args, "max_results")
+		delete(args, "cursor")
+		res := callTool(t, provider, "list_events", args)
+		var all ListEventsOutput
+		structuredOutput(t, res, &all)
+		var want []string
+		for _, ev := range all.Events {
+			want = append(want, ev.Account+"/"+ev.ID)
+		}
+		if all.NextCursor != "" || len(want) < 6 {
+			t.Fatalf("Expected a complete listing, got %v (cursor %q)", want, all.NextCursor)
+		}
+
+		for _, pageSize := range []int{1, 2, 4} {
+			args["max_results"] = pageSize
+			delete(args, "cursor")
+			var got []string
+			for page := 0; ; page++ {
+				if page > len(want)+1 {
+					t.Fatalf("Cursors did not end after %d pages of %d", page, pageSize)
+				}
+				res := callTool(t, provider, "list_events", args)
+				var out ListEventsOutput
+				structuredOutput(t, res, &out)
+				for _, ev := range out.Events {
+					got = append(got, ev.Account+"/"+ev.ID)
+				}
+				if out.NextCursor == "" {
+					break
+				}
+				if !out.Truncated || !strings.Contains(resultText(t, res), "pass cursor") {
+					t.Errorf("Expected a truncated page pointing at its cursor, got %+v", out)
+				}
+				args["cursor"] = out.NextCursor
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("Pages of %d (exclude_declined=%v): expected %v, got %v", pageSize, excludeDeclined, want, got)
+			}
+		}
+	}
+
+	args["max_results"] = 2
+	delete(args, "cursor")
+	res := callTool(t, provider, "list_events", args)
+	var out ListEventsOutput
+	structuredOutput(t, res, &out)
+	args["cursor"] = out.NextCursor
+	args["query"] = "standup"
+	res = callTool(t, provider, "list_events", args)
+	if !res.IsError || !strings.Contains(resultText(t, res), "cursor does not match the query") {
+		t.Errorf("Expected a mismatched cursor to fail, got %q", resultText(t, res))
+	}
+}
+
+// TestGoogleProviderCalendarCursor follows list_calendars cursors across accounts
+func TestGoogleProviderCalendarCursor(t *testing.T) {
+	accounts := seededFakeAccounts()
+	accounts["personal"].pageSize = 1
+	provider := newFakeGoogleProvider(t, accounts)
+
+	listAll := func(maxResults int) []string {
+		var got []string
+		args := map[string]any{"max_results": maxResults}
+		for page := 0; page < 10; page++ {
+			res := callTool(t, provider, "list_calendars", args)
+			var out ListCalendarsOutput
+			structuredOutput(t, res, &out)
+			if len(out.Calendars) > maxResults {
+				t.Errorf("Expected at most %d calendars, got %d", maxResults, len(out.Calendars))
+			}
+			for _, cal := range out.Calendars {
+				got = append(got, cal.Account+"/"+cal.ID)
+			}
+			if out.NextCursor == "" {
+				break
+			}
+			args["cursor"] = out.NextCursor
+		}
+		return got
+	}
+
+	// max_results counts the calendars of all accounts, listed in account order
+	want := []string{
+		"personal/me@example.com", "personal/fr.french#holiday@group.v.calendar.google.com",
+		"work/me@work.example.com", "work/team@group.calendar.google.com", "work/oncall@group.calendar.google.com",
+	}
+	if got := listAll(1); !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	// Each page of the personal calendar list is read once
+	var reads []string
+	for _, req := range accounts["personal"].requestLog() {
+		if strings.HasPrefix(req, "GET /users/me/calendarList?") {
+			reads = append(reads, req)
+		}
+	}
+	if len(reads) != 2 || strings.Contains(reads[0], "pageToken") || !strings.Contains(reads[1], "pageToken=1") {
+		t.Errorf("Expected the second page to continue from the first, got %v", reads)
+	}
+
+	// A page may end within the calendars of an account
+	if got := listAll(3); !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	// Pages are filled with selected calendars, skipping the unselected ones
+	work := accounts["work"]
+	work.mu.Lock()
+	work.calendars[1].Selected, work.calendars[2].Selected = false, true
+	work.mu.Unlock()
+	args := map[string]any{"account": "work", "max_results": 1, "selected_only": true}
+	for _, want := range []string{"me@work.example.com", "oncall@group.calendar.google.com"} {
+		res := callTool(t, provider, "list_calendars", args)
+		var out ListCalendarsOutput
+		structuredOutput(t, res, &out)
+		var ids []string
+		for _, cal := range out.Calendars {
+			ids = append(ids, cal.ID)
+		}
+		if strings.Join(ids, ",") != want {
+			t.Fatalf("Expected %s, got %v", want, ids)
+		}
+		args["cursor"] = out.NextCursor
+	}
+	if args["cursor"] != "" {
+		t.Errorf("Expected no cursor after the last selected calendar")
+	}
+}
+
+// TestGoogleProviderCalendarPagination verifies the whole calendar list is returned
+func TestGoogleProviderCalendarPagination(t *testing.T) {
+	accounts := seededFakeAccounts()
+	accounts["personal"].pageSize = 1
+	provider := newFakeGoogleProvider(t, accounts)
+
+	res := callTool(t, provider, "list_calendars", map[string]any{"account": "personal"})
+	var out ListCalendarsOutput
+	structuredOutput(t, res, &out)
+	if len(out.Calendars) != 2 || out.Truncated {
+		t.Errorf("Expected 2 untruncated calendars, got %+v", out)
+	}
+
+	res = callTool(t, provider, "list_calendars", map[string]any{"account": "personal", "max_results": 1})
+	structuredOutput(t, res, &out)
+	if len(out.Calendars) != 1 || !out.Truncated {
+		t.Errorf("Expected 1 truncated calendar, got %+v", out)
+	}
+}
+
+// TestGoogleProviderMergedEvents verifies events from all accounts are merged chronologically
+func TestGoogleProviderMergedEvents(t *testing.T) {
+	provider := newFakeGoogleProvider(t, seededFakeAccounts())
+
+	res := callTool(t, provider, "list_events", map[string]any{
+		"time_min": "2026-02-02T00:00:00Z",
+	})
+	var out ListEventsOutput
+	structuredOutput(t, res, &out)
+
+	var ids []string
+	for _, ev := range out.Events {
+		ids = append(ids, ev.ID)
+	}
+	want := []string{"standup-20260202", "dentist", "review-20260203", "ski-trip"}
+	if !slices.Equal(ids, want) {
+		t.Errorf("Expected %v, got %v", want, ids)
+	}
+
+	res = callTool(t, provider, "list_events", map[string]any{
+		"time_min":    "2026-02-02T00:00:00Z",
+		"max_results": 2,
+	})
+	structuredOutput(t, res, &out)
+	if len(out.Events) != 2 || out.Events[1].ID != "dentist" || !out.Truncated {
+		t.Errorf("Expected the first 2 merged events and truncation, got %+v", out)
+	}
+}
+
+// TestGoogleProviderMultipleCalendars verifies list_events across several calendars of an account
+func TestGoogleProviderMultipleCalendars(t *testing.T) {
+	tests := []struct {
+		name string
+		args map[string]any
+		want []string
+	}{
+		{
+			name: "explicit calendar list",
+			args: map[string]any{"calendar_ids": []string{"primary", "oncall@group.calendar.google.com"}},
+			want: []string{"oncall-week6", "standup-20260202", "review-20260203"},
+		},
+		{
+			name: "calendar_id and calendar_ids are combined",
+			args: map[string]any{"calendar_id": "team@group.calendar.google.com", "calendar_ids": []string{"team@group.calendar.google.com", "primary"}},
+			want: []string{"standup-20260202", "review-20260203", "offsite"},
+		},
+		{
+			name: "primary and the primary calendar's ID are the same calendar",
+			args: map[string]any{"calendar_ids": []string{"primary", "me@work.example.com"}},
+			want: []string{"standup-20260202", "review-20260203"},
+		},
+		{
+			name: "all selected calendars",
+			args: map[string]any{"all_calendars": true},
+			want: []string{"standup-20260202", "review-20260203", "offsite"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := newFakeGoogleProvider(t, seededFakeAccounts())
+
+			args := map[string]any{"account": "work", "time_min": "2026-02-02T00:00:00Z"}
+			maps.Copy(args, tt.args)
+			res := callTool(t, provider, "list_events", args)
+			var out ListEventsOutput
+			structuredOutput(t, res, &out)
+
+			var ids []string
+			for _, ev := range out.Events {
+				ids = append(ids, ev.ID)
+			}
+			if !slices.Equal(ids, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, ids)
+			}
+		})
+	}
+}
+
+// TestGoogleProviderCalendarTagging verifies events carry their calendar ID and name
+func TestGoogleProviderCalendarTagging(t *testing.T) {
+	provider := newFakeGoogleProvider(t, seededFakeAccounts())
+
+	res := callTool(t, provider, "list_events", map[string]any{
+		"account":       "work",
+		"time_min":      "2026-02-04T00:00:00Z",
+		"time_max":      "2026-02-05T00:00:00Z",
+		"all_calendars": true,
+	})
+	var out ListEventsOutput
+	structuredOutput(t, res, &out)
+
+	if len(out.Events) != 1 {
+		t.Fatalf("Expected the offsite only, got %+v", out.Events)
+	}
+	ev := out.Events[0]
+	if ev.CalendarID != "team@group.calendar.google.com" || ev.CalendarName != "Team" {
+		t.Errorf("Expected team calendar tagging, got %s/%s", ev.CalendarID, ev.CalendarName)
+	}
+	if text := resultText(t, res); !strings.Contains(text, "Team offsite (Team)") {
+		t.Errorf("Expected calendar name in text, got %q", text)
+	}
+}
+
+// TestGoogleProviderCalendarFailure verifies one failing calendar is reported without losing the others
+func TestGoogleProviderCalendarFailure(t *testing.T) {
+	provider := newFakeGoogleProvider(t, seededFakeAccounts())
+
+	res := callTool(t, provider, "list_events", map[string]any{
+		"account":      "work",
+		"time_min":     "2026-02-02T00:00:00Z",
+		"calendar_ids": []string{"primary", "gone@group.calendar.google.com"},
+	})
+	var out ListEventsOutput
+	structuredOutput(t, res, &out)
+
+	if len(out.Events) != 2 {
+		t.Errorf("Expected the 2 primary events, got %+v", out.Events)
+	}
+	if len(out.Errors) != 1 || out.Errors[0].CalendarID != "gone@group.calendar.google.com" {
+		t.Errorf("Expected an error for the missing calendar, got %+v", out.Errors)
+	}
+}
+
+// TestGoogleProviderCalendarNames verifies every tool accepts calendar names and aliases
+func TestGoogleProviderCalendarNames(t *testing.T) {
+	provider := newFakeGoogleProvider(t, seededFakeAccounts())
+
+	// A name only known to one account does not fail the others
+	res := callTool(t, provider, "list_events", map[string]any{
+		"calendar_id": "team",
+		"time_min":    "2026-02-02T00:00:00Z",
+	})
+	var events ListEventsOutput
+	structuredOutput(t, res, &events)
+	if len(events.Events) != 1 || events.Events[0].ID != "offsite" || len(events.Errors) != 0 {
+		t.Errorf("Expected the team offsite only, got %+v", events)
+	}
+
+	res = callTool(t, provider, "get_event", map[string]any{
+		"account":     "personal",
+		"calendar_id": "Holidays in France",
+		"event_id":    "holiday",
+	})
+	var event GetEventOutput
+	structuredOutput(t, res, &event)
+	if event.Event.CalendarID != "fr.french#holiday@group.v.calendar.google.com" {
+		t.Errorf("Expected the holiday calendar ID, got %q", event.Event.CalendarID)
+	}
+
+	res = callTool(t, provider, "check_availability", map[string]any{
+		"account":   "work",
+		"calendars": []string{"pager"},
+		"time_min":  "2026-02-02T00:00:00Z",
+		"time_max":  "2026-02-03T00:00:00Z",
+	})
+	var busy CheckAvailabilityOutput
+	structuredOutput(t, res, &busy)
+	if len(busy.BusyPeriods) != 1 {
+		t.Errorf("Expected the on-call block, got %+v", busy.BusyPeriods)
+	}
+
+	res = callTool(t, provider, "list_events", map[string]any{"calendar_id": "birthdays"})
+	if !res.IsError || !strings.Contains(resultText(t, res), "no calendar matching 'birthdays' in any account") {
+		t.Errorf("Expected an unknown calendar error, got %+v", res)
+	}
+}
+
+// TestGoogleProviderFindFreeSlots runs find_free_slots end to end in the user's time zone
+func TestGoogleProviderFindFreeSlots(t *testing.T) {
+	provider := newFakeGoogleProvider(t, seededFakeAccounts())
+
+	res := callTool(t, provider, "find_free_slots", map[string]any{
+		"time_min":         "2026-02-03T00:00:00+01:00",
+		"time_max":         "2026-02-04T00:00:00+01:00",
+		"duration_minutes": 60,
+		"time_zone":        "Europe/Paris",
+	})
+	var out FindFreeSlotsOutput
+	structuredOutput(t, res, &out)
+
+	// The dentist (09:00-09:45) and the design review (09:00-10:00) are merged
+	if out.TimeZone != "Europe/Paris" || len(out.Slots) != 1 {
+		t.Fatalf("Expected a single Paris slot, got %+v", out)
+	}
+	if got := out.Slots[0].Start.Format("15:04-07:00"); got != "10:00+01:00" {
+		t.Errorf("Expected the slot to start at 10:00+01:00, got %s", got)
+	}
+	if !strings.Contains(resultText(t, res), "1. Tue 2026-02-03 10:00 - 17:00 (420 min)") {
+		t.Errorf("Unexpected text %q", resultText(t, res))
+	}
+}
+
+// TestGoogleProviderNextEvent checks the events in progress and the next one,
+// skipping declined and all-day events unless asked, and events that do not
+// make the user busy
+func TestGoogleProviderNextEvent(t *testing.T) {
+	accounts := seededFakeAccounts()
+	accounts["work"].addEvents("me@work.example.com",
+		&calendar.Event{
+			Id:        "wl-office",
+			Summary:   "Office",
+			Status:    "confirmed",
+			EventType: "workingLocation",
+			Start:     &calendar.EventDateTime{Date: "2026-02-03"},
+			End:       &calendar.EventDateTime{Date: "2026-02-04"},
+			WorkingLocationProperties: &calendar.EventWorkingLocationProperties{
+				Type:           "officeLocation",
+				OfficeLocation: &calendar.EventWorkingLocationPropertiesOfficeLocation{Label: "Paris office"},
+			},
+		},
+		&calendar.Event{
+			Id:           "hold",
+			Summary:      "Focus hold",
+			Status:       "confirmed",
+			Start:        &calendar.EventDateTime{DateTime: "2026-02-03T03:00:00-05:00"},
+			End:          &calendar.EventDateTime{DateTime: "2026-02-03T04:00:00-05:00"},
+			Transparency: "transparent",
+		},
+		&calendar.Event{
+			Id:      "conference",
+			Summary: "Conference",
+			Status:  "confirmed",
+			Start:   &calendar.EventDateTime{Date: "2026-02-05"},
+			End:     &calendar.EventDateTime{Date: "2026-02-06"},
+		},
+	)
+	provider := newFakeGoogleProvider(t, accounts)
+	now := time.Date(2026, 2, 3, 8, 30, 0, 0, time.UTC)
+
+	out, err := nextEvent(context.Background(), provider, NextEventInput{}, now)
+	if err != nil {
+		t.Fatalf("nextEvent failed: %v", err)
+	}
+	var current []string
+	for _, ev := range out.Current {
+		current = append(current, ev.ID)
+	}
+	if !slices.Equal(current, []string{"dentist", "review-20260203"}) {
+		t.Errorf("Expected the dentist and the review in progress, got %v", current)
+	}
+	if out.Next == nil || out.Next.ID != "standup-20260209" {
+		t.Fatalf("Expected the next standup, got %+v", out.Next)
+	}
+	if out.StartsIn != "6d 6h" || out.MinutesUntilStart != 9000 {
+		t.Errorf("Unexpected time until start %q (%d min)", out.StartsIn, out.MinutesUntilStart)
+	}
+	if out.TimeZone != "Europe/Paris" || out.Now.Format("15:04") != "09:30" {
+		t.Errorf("Expected now in Paris, got %s (%s)", out.Now, out.TimeZone)
+	}
+
+	// All-day events in progress count when asked, except working locations
+	out, err = nextEvent(context.Background(), provider, NextEventInput{IncludeAllDay: true}, now)
+	if err != nil {
+		t.Fatalf("nextEvent failed: %v", err)
+	}
+	if len(out.Current) != 2 {
+		t.Errorf("Expected the same events in progress, got %+v", out.Current)
+	}
+
+	tests := []struct {
+		name  string
+		input NextEventInput
+		want  string
+	}{
+		{"all day", NextEventInput{IncludeAllDay: true}, "conference"},
+		{"declined", NextEventInput{IncludeAllDay: true, IncludeDeclined: true}, "offsite"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := nextEvent(context.Background(), provider, tt.input, now)
+			if err != nil {
+				t.Fatalf("nextEvent failed: %v", err)
+			}
+			if out.Next == nil || out.Next.ID != tt.want {
+				t.Errorf("Expected %s next, got %+v", tt.want, out.Next)
+			}
+		})
+	}
+
+	out, err = nextEvent(context.Background(), provider, NextEventInput{WithinDays: 1}, now.AddDate(0, 0, 30))
+	if err != nil {
+		t.Fatalf("nextEvent failed: %v", err)
+	}
+	if out.Next != nil || len(out.Current) != 0 {
+		t.Errorf("Expected nothing in the next day, got %+v", out)
+	}
+}
+
+// TestGoogleProviderAgenda groups a week of events by day across accounts
+func TestGoogleProviderAgenda(t *testing.T) {
+	provider := newFakeGoogleProvider(t, seededFakeAccounts())
+
+	res := callTool(t, provider, "get_agenda", map[string]any{
+		"view":          "week",
+		"date":          "2026-02-04",
+		"all_calendars": true,
+		"time_zone":     "Europe/Paris",
+	})
+	var out AgendaOutput
+	structuredOutput(t, res, &out)
+
+	if out.View != "week" || len(out.Days) != 7 || out.Days[0].Date != "2026-02-02" || out.Days[6].Weekday != "Sunday" {
+		t.Fatalf("Expected the week of Monday 2026-02-02, got %+v", out.Days)
+	}
+	ids := func(day AgendaDay) []string {
+		var ids []string
+		for _, item := range day.Items {
+			ids = append(ids, item.Event.ID)
+		}
+		return ids
+	}
+	if got := ids(out.Days[1]); !slices.Equal(got, []string{"dentist", "review-20260203"}) {
+		t.Errorf("Expected the dentist and the review on Tuesday, got %v", got)
+	}
+	if got := out.Days[1].Items[0].Overlaps; len(got) != 1 || got[0].Account != "work" || got[0].EventID != "review-20260203" {
+		t.Errorf("Expected the dentist to overlap the review, got %v", got)
+	}
+	// The declined offsite is left out
+	if got := ids(out.Days[2]); len(got) != 0 {
+		t.Errorf("Expected nothing on Wednesday, got %v", got)
+	}
+	for i, day := range out.Days[4:] {
+		if len(day.Items) != 1 || day.Items[0].Event.ID != "ski-trip" || day.Items[0].Day != i+1 || day.Items[0].Days != 3 {
+			t.Errorf("Expected day %d of the ski trip on %s, got %+v", i+1, day.Date, day.Items)
+		}
+	}
+
+	text := resultText(t, res)
+	for _, want := range []string{
+		"Agenda for Mon 2026-02-02 - Sun 2026-02-08 (Europe/Paris)",
+		"Tuesday 2026-02-03\n- 09:00-09:45 [personal] Dentist\n  Location: 12 rue de la Paix, Paris\n  Overlaps with: Design review",
+		"Wednesday 2026-02-04\n- No events",
+		"- all day [personal] Ski trip (day 2 of 3)",
+		"- 15:30-15:45 [work] Standup\n  Join: https://meet.google.com/abc-defg-hij",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in %q", want, text)
+		}
+	}
+
+	res = callTool(t, provider, "get_agenda", map[string]any{
+		"view":      "work_week",
+		"date":      "2026-02-04",
+		"time_zone": "Europe/Paris",
+	})
+	structuredOutput(t, res, &out)
+	if len(out.Days) != 5 || out.Days[4].Date != "2026-02-06" {
+		t.Errorf("Expected Monday to Friday, got %+v", out.Days)
+	}
+}
+
+// TestGoogleProviderFindConflicts finds double-bookings between accounts,
+// ignoring declined, free and duplicated events
+func TestGoogleProviderFindConflicts(t *testing.T) {
+	accounts := seededFakeAccounts()
+	accounts["work"].addEvents("me@work.example.com",
+		&calendar.Event{
+			Id:      "one-on-one",
+			Summary: "1:1",
+			Status:  "confirmed",
+			Start:   &calendar.EventDateTime{DateTime: "2026-02-03T04:00:00-05:00"},
+			End:     &calendar.EventDateTime{DateTime: "2026-02-03T04:30:00-05:00"},
+		},
+		&calendar.Event{
+			Id:           "focus",
+			Summary:      "Focus time",
+			Status:       "confirmed",
+			Transparency: "transparent",
+			Start:        &calendar.EventDateTime{DateTime: "2026-02-03T03:00:00-05:00"},
+			End:          &calendar.EventDateTime{DateTime: "2026-02-03T05:00:00-05:00"},
+		},
+		&calendar.Event{
+			Id:        "all-hands",
+			Summary:   "All hands",
+			Status:    "confirmed",
+			Start:     &calendar.EventDateTime{DateTime: "2026-02-03T03:15:00-05:00"},
+			End:       &calendar.EventDateTime{DateTime: "2026-02-03T03:45:00-05:00"},
+			Attendees: []*calendar.EventAttendee{{Email: "me@work.example.com", Self: true, ResponseStatus: "declined"}},
+		},
+	)
+	// The dentist is also on the team calendar, as the same meeting
+	accounts["personal"].events["me@example.com"][0].ICalUID = "dentist@example.com"
+	dentist := *accounts["personal"].events["me@example.com"][0]
+	accounts["work"].addEvents("team@group.calendar.google.com", &dentist)
+	provider := newFakeGoogleProvider(t, accounts)
+
+	res := callTool(t, provider, "find_conflicts", map[string]any{
+		"time_min":  "2026-02-03",
+		"time_zone": "Europe/Paris",
+	})
+	var out FindConflictsOutput
+	structuredOutput(t, res, &out)
+
+	var got []string
+	for _, c := range out.Conflicts {
+		got = append(got, fmt.Sprintf("%s %s/%s %d", c.Kind, c.Events[0].ID, c.Events[1].ID, c.Minutes))
+	}
+	want := []string{
+		"overlap dentist/review-20260203 45",
+		"back_to_back review-20260203/one-on-one 0",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if out.Checked != 3 {
+		t.Errorf("Expected 3 busy events, got %d", out.Checked)
+	}
+
+	text := resultText(t, res)
+	for _, line := range []string{
+		"Found 1 hard conflict(s) and 1 back-to-back pair(s) among 3 event(s)",
+		"- Tue 2026-02-03 09:00-09:45 (45 min): [personal] Dentist 09:00-09:45 and [work] Design review 09:00-10:00",
+		"- Tue 2026-02-03 10:00: [work] Design review ends, [work] 1:1 starts at 10:00 (no break)",
+	} {
+		if !strings.Contains(text, line) {
+			t.Errorf("Expected %q in %q", line, text)
+		}
+	}
+}
+
+// TestGoogleProviderFindConflictsPaging verifies conflicts are looked for
+// past the first page of events
+func TestGoogleProviderFindConflictsPaging(t *testing.T) {
+	accounts := seededFakeAccounts()
+	start := time.Date(2026, time.March, 2, 0, 0, 0, 0, time.UTC)
+	for i := range 300 {
+		at := start.Add(time.Duration(i) * 2 * time.Hour)
+		accounts["work"].addEvents("me@work.example.com", &calendar.Event{
+			Id:      fmt.Sprintf("task-%03d", i),
+			Summary: "Task",
+			Status:  "confirmed",
+			Start:   &calendar.EventDateTime{DateTime: at.Format(time.RFC3339)},
+			End:     &calendar.EventDateTime{DateTime: at.Add(30 * time.Minute).Format(time.RFC3339)},
+		})
+	}
+	// The last task overlaps a meeting on the team calendar
+	last := start.Add(299 * 2 * time.Hour)
+	accounts["work"].addEvents("team@group.calendar.google.com", &calendar.Event{
+		Id:      "late-sync",
+		Summary: "Late sync",
+		Status:  "confirmed",
+		Start:   &calendar.EventDateTime{DateTime: last.Add(15 * time.Minute).Format(time.RFC3339)},
+		End:     &calendar.EventDateTime{DateTime: last.Add(time.Hour).Format(time.RFC3339)},
+	})
+	provider := newFakeGoogleProvider(t, accounts)
+
+	res := callTool(t, provider, "find_conflicts", map[string]any{
+		"account":   "work",
+		"time_min":  "2026-03-01",
+		"time_max":  "2026-04-01",
+		"time_zone": "UTC",
+	})
+	var out FindConflictsOutput
+	structuredOutput(t, res, &out)
+
+	if out.Checked != 301 || out.Truncated || len(out.Conflicts) != 1 || out.Conflicts[0].Events[1].ID != "late-sync" {
+		t.Errorf("Expected the late sync conflict among 301 events, got %d events (truncated: %v) and %+v", out.Checked, out.Truncated, out.Conflicts)
+	}
+}
+
+// TestGoogleProviderCalendarStats measures a work week of meetings across accounts
+func TestGoogleProviderCalendarStats(t *testing.T) {
+	provider := newFakeGoogleProvider(t, seededFakeAccounts())
+
+	res := callTool(t, provider, "calendar_stats", map[string]any{
+		"time_min":  "2026-02-02",
+		"time_max":  "2026-02-06",
+		"time_zone": "Europe/Paris",
+	})
+	var out CalendarStatsOutput
+	structuredOutput(t, res, &out)
+
+	// The standup is a one-on-one with the lead, the review a group meeting,
+	// the dentist a solo event; the offsite is declined and the ski trip free
+	if out.Meetings != 2 || out.OneOnOneMeetings != 1 || out.GroupMeetings != 1 || out.SoloEvents != 1 {
+		t.Errorf("Unexpected meeting counts %+v", out)
+	}
+	if out.MeetingMinutes != 75 || out.FocusMinutes != 465+420+3*480 {
+		t.Errorf("Expected 75 minutes in meetings and 2325 of focus, got %d and %d", out.MeetingMinutes, out.FocusMinutes)
+	}
+	if len(out.Days) != 5 || out.Days[0].LongestFreeMinutes != 390 || out.Days[1].Meetings != 1 || out.Days[1].FocusMinutes != 420 {
+		t.Errorf("Unexpected days %+v", out.Days)
+	}
+	if len(out.Weeks) != 1 || out.Weeks[0].Week != "2026-W06" || out.Weeks[0].Meetings != 2 {
+		t.Errorf("Unexpected weeks %+v", out.Weeks)
+	}
+	if b := out.LongestFreeBlock; b == nil || b.DurationMinutes != 480 || b.Start.Format("2006-01-02 15:04") != "2026-02-04 09:00" {
+		t.Errorf("Expected Wednesday to be the longest free block, got %+v", b)
+	}
+
+	var collaborators []string
+	for _, c := range out.Collaborators {
+		collaborators = append(collaborators, fmt.Sprintf("%s %d %d", c.Email, c.Meetings, c.Minutes))
+	}
+	if want := []string{"lead@work.example.com 2 75", "alice@work.example.com 1 60"}; !slices.Equal(collaborators, want) {
+		t.Errorf("Expected collaborators %v, got %v", want, collaborators)
+	}
+	if len(out.Accounts) != 1 || out.Accounts[0].Account != "work" || len(out.Calendars) != 1 || out.Calendars[0].Minutes != 75 {
+		t.Errorf("Unexpected breakdown %+v %+v", out.Accounts, out.Calendars)
+	}
+
+	text := resultText(t, res)
+	for _, want := range []string{
+		"Meetings: 2 (1 one-on-one, 1 group), 1h 15m in meetings",
+		"Longest meeting-free block: Wed 2026-02-04 09:00-17:00 (8h)",
+		"- Tue 2026-02-03: 1 meeting(s), 1h in meetings, 7h focus",
+		"- Alice Martin <alice@work.example.com>: 1 meeting(s), 1h",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in %q", want, text)
+		}
+	}
+}
+
+// TestGoogleProviderCalendarStatsPaging verifies statistics cover every event
+// of a range longer than a page of events, including events sharing a start
+// time across pages
+func TestGoogleProviderCalendarStatsPaging(t *testing.T) {
+	accounts := seededFakeAccounts()
+	accounts["work"].pageSize = 7
+	start := time.Date(2026, time.March, 2, 0, 0, 0, 0, time.UTC)
+	for i := range 300 {
+		// Three tasks of different lengths start at each slot
+		at := start.Add(time.Duration(i/3) * 2 * time.Hour)
+		accounts["work"].addEvents("me@work.example.com", &calendar.Event{
+			Id:      fmt.Sprintf("task-%03d", i),
+			Summary: "Task",
+			Status:  "confirmed",
+			Start:   &calendar.EventDateTime{DateTime: at.Format(time.RFC3339)},
+			End:     &calendar.EventDateTime{DateTime: at.Add(time.Duration(30-i%3*10) * time.Minute).Format(time.RFC3339)},
+		})
+	}
+	provider := newFakeGoogleProvider(t, accounts)
+
+	res := callTool(t, provider, "calendar_stats", map[string]any{
+		"account":   "work",
+		"calendars": []string{"primary"},
+		"time_min":  "2026-03-01",
+		"time_max":  "2026-04-01",
+		"time_zone": "UTC",
+	})
+	var out CalendarStatsOutput
+	structuredOutput(t, res, &out)
+
+	if out.SoloEvents != 300 || out.Truncated {
+		t.Errorf("Expected the 300 events of the month, got %d (truncated: %v)", out.SoloEvents, out.Truncated)
+	}
+}
+
+// seedSpecialEvents adds working location, out of office and focus time
+// events to the work calendar
+func seedSpecialEvents(accounts map[string]*fakeCalendarAPI) {
+	accounts["work"].addEvents("me@work.example.com",
+		&calendar.Event{
+			Id:        "wl-home",
+			Summary:   "Home",
+			EventType: "workingLocation",
+			Start:     &calendar.EventDateTime{Date: "2026-02-04"},
+			End:       &calendar.EventDateTime{Date: "2026-02-05"},
+			WorkingLocationProperties: &calendar.EventWorkingLocationProperties{
+				Type:       "homeOffice",
+				HomeOffice: map[string]any{},
+			},
+			Transparency: "transparent",
+		},
+		&calendar.Event{
+			Id:        "wl-office",
+			Summary:   "Office",
+			EventType: "workingLocation",
+			Start:     &calendar.EventDateTime{Date: "2026-02-05"},
+			End:       &calendar.EventDateTime{Date: "2026-02-06"},
+			WorkingLocationProperties: &calendar.EventWorkingLocationProperties{
+				Type:           "officeLocation",
+				OfficeLocation: &calendar.EventWorkingLocationPropertiesOfficeLocation{Label: "Paris office", BuildingId: "PAR-1", FloorId: "3", DeskId: "12"},
+			},
+			Transparency: "transparent",
+		},
+		&calendar.Event{
+			Id:        "focus-20260205",
+			Summary:   "Focus time",
+			EventType: "focusTime",
+			Start:     &calendar.EventDateTime{DateTime: "2026-02-05T10:00:00-05:00"},
+			End:       &calendar.EventDateTime{DateTime: "2026-02-05T12:00:00-05:00"},
+			FocusTimeProperties: &calendar.EventFocusTimeProperties{
+				AutoDeclineMode: "declineOnlyNewConflictingInvitations",
+				ChatStatus:      "doNotDisturb",
+			},
+		},
+		&calendar.Event{
+			Id:        "ooo-20260206",
+			Summary:   "Out of office",
+			EventType: "outOfOffice",
+			Start:     &calendar.EventDateTime{DateTime: "2026-02-06T00:00:00-05:00"},
+			End:       &calendar.EventDateTime{DateTime: "2026-02-07T00:00:00-05:00"},
+			OutOfOfficeProperties: &calendar.EventOutOfOfficeProperties{
+				AutoDeclineMode: "declineAllConflictingInvitations",
+				DeclineMessage:  "Back on Monday",
+			},
+		},
+	)
+}
+
+// TestGoogleProviderEventTypes checks event types and their properties are
+// exposed and can be filtered on
+func TestGoogleProviderEventTypes(t *testing.T) {
+	accounts := seededFakeAccounts()
+	seedSpecialEvents(accounts)
+	provider := newFakeGoogleProvider(t, accounts)
+
+	res := callTool(t, provider, "list_events", map[string]any{
+		"account":  "work",
+		"time_min": "2026-02-04T00:00:00-05:00",
+		"time_max": "2026-02-07T00:00:00-05:00",
+	})
+	var out ListEventsOutput
+	structuredOutput(t, res, &out)
+
+	types := make(map[string]Event)
+	for _, ev := range slices.Backward(out.Events) {
+		types[ev.EventType] = ev
+	}
+	if wl := types["workingLocation"].WorkingLocation; wl == nil || wl.Type != "homeOffice" || wl.Label != "Home" {
+		t.Errorf("Expected the home working location first, got %+v", wl)
+	}
+	if ft := types["focusTime"].FocusTime; ft == nil || ft.ChatStatus != "doNotDisturb" {
+		t.Errorf("Expected focus time properties, got %+v", ft)
+	}
+	if ooo := types["outOfOffice"].OutOfOffice; ooo == nil || ooo.AutoDeclineMode != "declineAllConflictingInvitations" {
+		t.Errorf("Expected out of office properties, got %+v", ooo)
+	}
+	if !strings.Contains(resultText(t, res), ": Focus time [focus time]") {
+		t.Errorf("Expected the focus time to be marked, got %q", resultText(t, res))
+	}
+
+	tests := []struct {
+		name string
+		args map[string]any
+		want []string
+	}{
+		{"include", map[string]any{"event_types": []string{"focusTime", "outOfOffice"}}, []string{"focus-20260205", "ooo-20260206"}},
+		{"exclude", map[string]any{"exclude_event_types": []string{"workingLocation", "focusTime", "outOfOffice"}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := map[string]any{"account": "work", "time_min": "2026-02-04T00:00:00-05:00", "time_max": "2026-02-07T00:00:00-05:00"}
+			maps.Copy(args, tt.args)
+			var out ListEventsOutput
+			structuredOutput(t, callTool(t, provider, "list_events", args), &out)
+			var ids []string
+			for _, ev := range out.Events {
+				ids = append(ids, ev.ID)
+			}
+			if !slices.Equal(ids, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, ids)
+			}
+		})
+	}
+
+	res = callTool(t, provider, "list_events", map[string]any{"event_types": []string{"meeting"}})
+	if !res.IsError || !strings.Contains(resultText(t, res), "invalid event type 'meeting'") {
+		t.Errorf("Expected an invalid event type error, got %q", resultText(t, res))
+	}
+
+	res = callTool(t, provider, "get_event", map[string]any{"account": "work", "calendar_id": "me@work.example.com", "event_id": "ooo-20260206"})
+	for _, want := range []string{"Type: out of office", "Auto-decline: declineAllConflictingInvitations", "Decline message: Back on Monday"} {
+		if !strings.Contains(resultText(t, res), want) {
+			t.Errorf("Expected %q in %q", want, resultText(t, res))
+		}
+	}
+}
+
+// TestGoogleProviderWorkingLocation answers where the user works day by day
+func TestGoogleProviderWorkingLocation(t *testing.T) {
+	accounts := seededFakeAccounts()
+	seedSpecialEvents(accounts)
+	provider := newFakeGoogleProvider(t, accounts)
+	now := time.Date(2026, 2, 2, 12, 0, 0, 0, time.UTC)
+
+	out, err := getWorkingLocation(context.Background(), provider, WorkingLocationInput{Date: "thursday", TimeZone: "America/New_York"}, now)
+	if err != nil {
+		t.Fatalf("getWorkingLocation failed: %v", err)
+	}
+	if len(out.Days) != 1 || out.Days[0].Date != "2026-02-05" || len(out.Days[0].Locations) != 1 {
+		t.Fatalf("Expected a single location on Thursday, got %+v", out.Days)
+	}
+	if got := formatWorkingLocation(out.Days[0].Locations[0].Location); got != "Paris office (floor 3, desk 12)" {
+		t.Errorf("Unexpected location %q", got)
+	}
+
+	out, err = getWorkingLocation(context.Background(), provider, WorkingLocationInput{Date: "this week", TimeZone: "America/New_York"}, now)
+	if err != nil {
+		t.Fatalf("getWorkingLocation failed: %v", err)
+	}
+	if len(out.Days) != 7 || out.Days[2].Locations[0].Location.Type != "homeOffice" || !out.Days[4].OutOfOffice || len(out.Days[0].Locations) != 0 {
+		t.Errorf("Unexpected week %+v", out.Days)
+	}
+
+	res := callTool(t, provider, "get_working_location", map[string]any{"date": "2026-02-05", "time_zone": "America/New_York"})
+	if want := "- Thu 2026-02-05: Paris office (floor 3, desk 12) [work]"; !strings.Contains(resultText(t, res), want) {
+		t.Errorf("Expected %q in %q", want, resultText(t, res))
+	}
+}
+
+// TestGoogleProviderSettings reads account settings, whose time zone applies
+// to accounts without a configured one
+func TestGoogleProviderSettings(t *testing.T) {
+	accounts := seededFakeAccounts()
+	accounts["personal"].settings = map[string]string{
+		"timezone":           "Europe/Paris",
+		"weekStart":          "1",
+		"format24HourTime":   "true",
+		"defaultEventLength": "30",
+		"locale":             "fr",
+	}
+	accounts["personal"].pageSize = 2
+	accounts["work"].settings = map[string]string{"timezone": "America/Chicago", "weekStart": "0", "format24HourTime": "false"}
+	accounts["work"].timeZone = ""
+	provider := newFakeGoogleProvider(t, accounts)
+
+	res := callTool(t, provider, "get_settings", map[string]any{})
+	var out GetSettingsOutput
+	structuredOutput(t, res, &out)
+	if len(out.Accounts) != 2 {
+		t.Fatalf("Expected the settings of both accounts, got %+v", out.Accounts)
+	}
+	personal, work := out.Accounts[0], out.Accounts[1]
+	if personal.TimeZone != "Europe/Paris" || personal.WeekStart != "Monday" || !personal.Format24HourTime || personal.DefaultEventLength != 30 || len(personal.Settings) != 5 {
+		t.Errorf("Unexpected personal settings %+v", personal)
+	}
+	if work.WeekStart != "Sunday" || work.Format24HourTime {
+		t.Errorf("Unexpected work settings %+v", work)
+	}
+	text := resultText(t, res)
+	for _, want := range []string{"[personal]\n- Time zone: Europe/Paris\n- Week starts on: Monday\n- Time format: 24-hour", "- Default event length: 30 minutes", "[work]\n- Time zone: America/Chicago"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in %q", want, text)
+		}
+	}
+
+	// The work account has no time_zone configured, so its settings' applies
+	res = callTool(t, provider, "get_event", map[string]any{"account": "work", "calendar_id": "primary", "event_id": "review-20260203"})
+	if text := resultText(t, res); !strings.Contains(text, "When: 2026-02-03 02:00 - 2026-02-03 03:00 (America/Chicago)") {
+		t.Errorf("Expected the review in the work settings' time zone, got %q", text)
+	}
+
+	// Weeks start on the day of the settings
+	res = callTool(t, provider, "get_agenda", map[string]any{"account": "work", "view": "week", "date": "2026-02-04"})
+	var agenda AgendaOutput
+	structuredOutput(t, res, &agenda)
+	if len(agenda.Days) != 7 || agenda.Days[0].Date != "2026-02-01" || agenda.Days[0].Weekday != "Sunday" {
+		t.Errorf("Expected the week of Sunday 2026-02-01, got %+v", agenda.Days)
+	}
+
+	// The settings are read once and reused by the following calls
+	fetches := 0
+	for _, req := range accounts["work"].requestLog() {
+		if strings.HasPrefix(req, "GET /users/me/settings?") {
+			fetches++
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("Expected the work settings to be read once, got %d reads", fetches)
+	}
+}
+
+// TestGoogleProviderColors resolves the color IDs of events and calendars
+func TestGoogleProviderColors(t *testing.T) {
+	accounts := seededFakeAccounts()
+	accounts["personal"].events["me@example.com"][0].ColorId = "11"
+	accounts["personal"].calendars[0].ColorId = "14"
+	provider := newFakeGoogleProvider(t, accounts)
+
+	res := callTool(t, provider, "get_colors", map[string]any{"account": "work"})
+	var colors GetColorsOutput
+	structuredOutput(t, res, &colors)
+	if len(colors.Event) != 4 || colors.Event[2].ID != "10" || colors.Event[2].Name != "Basil" || len(colors.Calendar) != 3 {
+		t.Errorf("Expected the palettes sorted by ID, got %+v", colors)
+	}
+	if text := resultText(t, res); !strings.Contains(text, "Event colors:\n- 1 Lavender (#a4bdfc)") || !strings.Contains(text, "- 24 Amethyst (#a47ae2)") {
+		t.Errorf("Unexpected text %q", text)
+	}
+
+	res = callTool(t, provider, "list_events", map[string]any{
+		"time_min": "2026-02-03T00:00:00+01:00",
+		"time_max": "2026-02-03T09:30:00+01:00",
+	})
+	var events ListEventsOutput
+	structuredOutput(t, res, &events)
+	if len(events.Events) != 2 || events.Events[0].Color == nil || *events.Events[0].Color != (Color{ID: "11", Name: "Tomato", Background: "#dc2127", Foreground: "#1d1d1d"}) {
+		t.Errorf("Expected the dentist in tomato, got %+v", events.Events)
+	}
+
+	res = callTool(t, provider, "get_event", map[string]any{"account": "personal", "calendar_id": "primary", "event_id": "dentist"})
+	if text := resultText(t, res); !strings.Contains(text, "Color: Tomato (#dc2127)") {
+		t.Errorf("Expected the event color in %q", text)
+	}
+
+	res = callTool(t, provider, "list_calendars", map[string]any{"account": "personal"})
+	var calendars ListCalendarsOutput
+	structuredOutput(t, res, &calendars)
+	if calendars.Calendars[0].ColorName != "Peacock" {
+		t.Errorf("Expected the calendar color name, got %+v", calendars.Calendars[0])
+	}
+
+	// The palette is only fetched for accounts with colored events
+	fetches := 0
+	for _, req := range accounts["work"].requestLog() {
+		if strings.HasPrefix(req, "GET /colors?") {
+			fetches++
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("Expected the work palette to be fetched by get_colors only, got %d fetches", fetches)
+	}
+
+	// The palette is fetched once and reused
+	fetches = 0
+	for _, req := range accounts["personal"].requestLog() {
+		if strings.HasPrefix(req, "GET /colors?") {
+			fetches++
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("Expected the personal palette to be fetched once, got %d fetches", fetches)
+	}
+}
+
+// TestGoogleProviderEventDetails checks get_event shows the event's details
+// and list_events only returns them when asked for
+func TestGoogleProviderEventDetails(t *testing.T) {
+	accounts := seededFakeAccounts()
+	work := accounts["work"].events["me@work.example.com"]
+	review := work[slices.IndexFunc(work, func(ev *calendar.Event) bool { return ev.Id == "review-20260203" })]
+	review.Visibility = "private"
+	review.Transparency = "transparent"
+	review.Creator = &calendar.EventCreator{Email: "lead@work.example.com"}
+	review.Created = "2026-01-20T14:00:00Z"
+	review.Updated = "2026-02-01T08:30:00Z"
+	review.Sequence = 2
+	hideGuests := false
+	review.GuestsCanSeeOtherGuests = &hideGuests
+	review.Reminders = &calendar.EventReminders{Overrides: []*calendar.EventReminder{{Method: "popup", Minutes: 10}, {Method: "email", Minutes: 1440}}}
+	review.Attachments = []*calendar.EventAttachment{{
+		Title:    "Design doc",
+		FileUrl:  "https://drive.google.com/open?id=doc1",
+		FileId:   "doc1",
+		MimeType: "application/vnd.google-apps.document",
+	}}
+	provider := newFakeGoogleProvider(t, accounts)
+
+	res := callTool(t, provider, "get_event", map[string]any{"account": "work", "calendar_id": "primary", "event_id": "review-20260203"})
+	var out GetEventOutput
+	structuredOutput(t, res, &out)
+	d := out.Event.Details
+	if d == nil || d.Sequence != 2 || d.Creator != "lead@work.example.com" || len(d.Attachments) != 1 || !d.GuestsCanInviteOthers || d.GuestsCanSeeOtherGuests {
+		t.Fatalf("Unexpected details %+v", d)
+	}
+	text := resultText(t, res)
+	for _, want := range []string{
+		"Shows as: free",
+		"Visibility: private",
+		"Reminders: popup 10m before, email 1d before",
+		"Attachments:\n- Design doc (application/vnd.google-apps.document) https://drive.google.com/open?id=doc1",
+		"Guests can: invite others",
+		"Created: 2026-01-20 09:00 by lead@work.example.com",
+		"Updated: 2026-02-01 03:30",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in %q", want, text)
+		}
+	}
+
+	args := map[string]any{
+		"account":  "work",
+		"time_min": "2026-02-03T00:00:00-05:00",
+		"time_max": "2026-02-04T00:00:00-05:00",
+	}
+	res = callTool(t, provider, "list_events", args)
+	var events ListEventsOutput
+	structuredOutput(t, res, &events)
+	if len(events.Events) != 1 || events.Events[0].Details != nil || events.Events[0].Transparency != "transparent" {
+		t.Errorf("Expected a compact review, got %+v", events.Events)
+	}
+
+	args["detail"] = "full"
+	res = callTool(t, provider, "list_events", args)
+	structuredOutput(t, res, &events)
+	if len(events.Events) != 1 || events.Events[0].Details == nil || events.Events[0].Details.Reminders == nil {
+		t.Errorf("Expected the review with its details, got %+v", events.Events)
+	}
+
+	args["detail"] = "verbose"
+	res = callTool(t, provider, "list_events", args)
+	if !res.IsError || !strings.Contains(resultText(t, res), "invalid detail 'verbose'") {
+		t.Errorf("Expected an invalid detail error, got %q", resultText(t, res))
+	}
+}
+
+// TestGoogleProviderFieldProjection checks the fields parameter limits both
+// what is returned and what is read from the API
+func TestGoogleProviderFieldProjection(t *testing.T) {
+	accounts := seededFakeAccounts()
+	provider := newFakeGoogleProvider(t, accounts)
+	args := map[string]any{
+		"account":  "work",
+		"time_min": "2026-02-03T00:00:00-05:00",
+		"time_max": "2026-02-04T00:00:00-05:00",
+		"fields":   []string{"minimal"},
+	}
+
+	res := callTool(t, provider, "list_events", args)
+	var out ListEventsOutput
+	structuredOutput(t, res, &out)
+	if len(out.Events) != 1 || out.Events[0].Description != "" || len(out.Events[0].Attendees) != 0 || out.Events[0].ResponseStatus != "needsAction" || out.Events[0].MeetingURL != "" {
+		t.Errorf("Expected a minimal review, got %+v", out.Events)
+	}
+	log := eventRequests(accounts["work"])
+	if last := log[len(log)-1]; !strings.Contains(last, "fields=nextPageToken%2Csummary%2CtimeZone%2Citems%28attendees%28email%2Cself%2CresponseStatus%29%2Cend%2ChtmlLink%2Cid%2Clocation%2Cstart%2Cstatus%2Csummary%29") {
+		t.Errorf("Expected a partial response to be requested, got %s", last)
+	}
+
+	// Meeting links are still extracted from the description left out
+	args["fields"] = []string{"conference", "attendees"}
+	res = callTool(t, provider, "list_events", args)
+	structuredOutput(t, res, &out)
+	if len(out.Events) != 1 || out.Events[0].Description != "" || len(out.Events[0].Attendees) != 5 || !strings.HasPrefix(out.Events[0].MeetingURL, "https://us02web.zoom.us/j/8123456789") {
+		t.Errorf("Expected the review's attendees and meeting link only, got %+v", out.Events)
+	}
+
+	// Filters read the fields they need without returning them
+	res = callTool(t, provider, "list_events", map[string]any{
+		"account":          "work",
+		"all_calendars":    true,
+		"time_min":         "2026-02-04T00:00:00-05:00",
+		"time_max":         "2026-02-05T00:00:00-05:00",
+		"exclude_declined": true,
+		"fields":           []string{"location"},
+	})
+	structuredOutput(t, res, &out)
+	if len(out.Events) != 0 {
+		t.Errorf("Expected the declined offsite to be left out, got %+v", out.Events)
+	}
+
+	args["fields"] = []string{"everything"}
+	res = callTool(t, provider, "list_events", args)
+	if !res.IsError || !strings.Contains(resultText(t, res), "invalid field 'everything'") {
+		t.Errorf("Expected an invalid field error, got %q", resultText(t, res))
+	}
+
+	res = callTool(t, provider, "get_event", map[string]any{"account": "work", "calendar_id": "primary", "event_id": "review-20260203", "fields": []string{"minimal"}})
+	var event GetEventOutput
+	structuredOutput(t, res, &event)
+	if event.Event.Summary != "Design review" || len(event.Event.Attendees) != 0 || event.Event.Details != nil {
+		t.Errorf("Expected a minimal review, got %+v", event.Event)
+	}
+	if text := resultText(t, res); strings.Contains(text, "Attendees:") || strings.Contains(text, "Shows as:") {
+		t.Errorf("Expected no attendees nor availability in %q", text)
+	}
+}
+
+// TestGoogleProviderPartialFailure verifies one failing account still returns the others
+func TestGoogleProviderPartialFailure(t *testing.T) {
+	accounts := seededFakeAccounts()
+	accounts["work"].failWith = 401
+	provider := newFakeGoogleProvider(t, accounts)
+
+	res := callTool(t, provider, "list_events", map[string]any{
+		"time_min": "2026-02-02T00:00:00Z",
+	})
+	var out ListEventsOutput
+	structuredOutput(t, res, &out)
+
+	if len(out.Events) != 2 {
+		t.Errorf("Expected the 2 personal events, got %+v", out.Events)
+	}
+	if len(out.Errors) != 1 || out.Errors[0].Account != "work" || !strings.Contains(out.Errors[0].Error, "401") {
+		t.Errorf("Expected a 401 error for work, got %+v", out.Errors)
+	}
+}
+
+// TestFakeCalendarAPIPagination checks the fake pages like the real API does
+func TestFakeCalendarAPIPagination(t *testing.T) {
+	api := seededFakeAccounts()["personal"]
+	api.pageSize = 1
+	srv := api.start(t)
+
+	var ids []string
+	token := ""
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatal("Too many pages")
+		}
+		list, err := srv.CalendarList.List().PageToken(token).Do()
+		if err != nil {
+			t.Fatalf("CalendarList.List() error: %v", err)
+		}
+		for _, item := range list.Items {
+			ids = append(ids, item.Id)
+		}
+		if list.NextPageToken == "" {
+			break
+		}
+		token = list.NextPageToken
+	}
+
+	if len(ids) != 2 {
+		t.Errorf("Expected 2 calendars over 2 pages, got %v", ids)
+	}
+}
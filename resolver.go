@@ -0,0 +1,263 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// calendarResolver turns the calendar references accepted by the tools - a
+// calendar ID, an alias configured for the account, or a calendar name - into
+// calendar IDs. Calendar lists are fetched lazily and cached per account for
+// the lifetime of the resolver, which is meant to be a single tool call.
+type calendarResolver struct {
+	provider CalendarProvider
+
+	mu    sync.Mutex
+	lists map[string][]Calendar
+}
+
+// CalendarNotFoundError reports a calendar reference that matched no calendar of the account
+type CalendarNotFoundError struct {
+	Account   string
+	Ref       string
+	Available []string
+}
+
+func (e *CalendarNotFoundError) Error() string {
+	msg := fmt.Sprintf("no calendar matching '%s' in account '%s'", e.Ref, e.Account)
+	if len(e.Available) > 0 {
+		msg += fmt.Sprintf(" (available: %s)", strings.Join(e.Available, ", "))
+	}
+	return msg
+}
+
+// AmbiguousCalendarError reports a calendar reference that matched several calendars
+type AmbiguousCalendarError struct {
+	Account    string
+	Ref        string
+	Candidates []Calendar
+}
+
+func (e *AmbiguousCalendarError) Error() string {
+	var candidates []string
+	for _, cal := range e.Candidates {
+		candidates = append(candidates, fmt.Sprintf("%s (%s)", cal.Name(), cal.ID))
+	}
+	return fmt.Sprintf("calendar '%s' is ambiguous in account '%s', use one of: %s",
+		e.Ref, e.Account, strings.Join(candidates, ", "))
+}
+
+func newCalendarResolver(provider CalendarProvider) *calendarResolver {
+	return &calendarResolver{
+		provider: provider,
+		lists:    make(map[string][]Calendar),
+	}
+}
+
+// resolve returns the calendar ID the reference designates in the account.
+// Empty references and "primary" are returned unchanged, and so are references
+// that look like calendar IDs, so that plain IDs never cost a calendar listing.
+// Names are matched case-insensitively, first exactly, then by words, then by
+// a small edit distance; a match on more than one calendar is an error.
+func (r *calendarResolver) resolve(ctx context.Context, account, ref string) (string, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || ref == "primary" {
+		return ref, nil
+	}
+
+	config, err := r.provider.AccountConfig(account)
+	if err != nil {
+		return "", err
+	}
+	// An alias spelled exactly like the reference wins over aliases differing
+	// only by case, which are tried in sorted order
+	if id, ok := config.CalendarAliases[ref]; ok {
+		return id, nil
+	}
+	for _, alias := range slices.Sorted(maps.Keys(config.CalendarAliases)) {
+		if strings.EqualFold(alias, ref) {
+			return config.CalendarAliases[alias], nil
+		}
+	}
+
+	if strings.Contains(ref, "@") {
+		return ref, nil
+	}
+
+	calendars, err := r.calendars(ctx, account)
+	if err != nil {
+		return "", err
+	}
+
+	for _, cal := range calendars {
+		if cal.ID == ref {
+			return cal.ID, nil
+		}
+	}
+
+	matches := matchCalendarName(calendars, ref)
+	switch len(matches) {
+	case 0:
+		var available []string
+		for _, cal := range calendars {
+			available = append(available, cal.Name())
+		}
+		return "", &CalendarNotFoundError{Account: account, Ref: ref, Available: available}
+	case 1:
+		return matches[0].ID, nil
+	default:
+		return "", &AmbiguousCalendarError{Account: account, Ref: ref, Candidates: matches}
+	}
+}
+
+// calendars returns the cached calendar list of the account
+func (r *calendarResolver) calendars(ctx context.Context, account string) ([]Calendar, error) {
+	r.mu.Lock()
+	list, ok := r.lists[account]
+	r.mu.Unlock()
+	if ok {
+		return list, nil
+	}
+
+	list, _, err := r.provider.ListCalendars(ctx, account, CalendarQuery{MaxResults: 1000})
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.lists[account] = list
+	r.mu.Unlock()
+	return list, nil
+}
+
+// primaryID returns the ID of the account's primary calendar, or an empty
+// string if the calendar list has none
+func (r *calendarResolver) primaryID(ctx context.Context, account string) (string, error) {
+	calendars, err := r.calendars(ctx, account)
+	if err != nil {
+		return "", err
+	}
+	for _, cal := range calendars {
+		if cal.Primary {
+			return cal.ID, nil
+		}
+	}
+	return "", nil
+}
+
+// cachedName returns the name the user sees for a calendar of the account,
+// if the account's calendar list was already fetched
+func (r *calendarResolver) cachedName(account, calendarID string) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, cal := range r.lists[account] {
+		if cal.ID == calendarID {
+			return cal.Name(), true
+		}
+	}
+	return "", false
+}
+
+// isCalendarNotFound reports whether err is a CalendarNotFoundError
+func isCalendarNotFound(err error) bool {
+	var notFound *CalendarNotFoundError
+	return errors.As(err, &notFound)
+}
+
+// matchCalendarName returns the calendars whose name best matches the reference,
+// trying progressively looser matching until one stage finds candidates
+func matchCalendarName(calendars []Calendar, ref string) []Calendar {
+	want := normalizeName(ref)
+	if want == "" {
+		return nil
+	}
+
+	stages := []func(name string) bool{
+		// Exact name
+		func(name string) bool { return name == want },
+		// Every word of the reference starts a word of the name
+		func(name string) bool { return containsWords(name, want) },
+		// A typo or two
+		func(name string) bool { return editDistance(name, want) <= max(1, len(want)/5) },
+	}
+
+	// The name the user gave a calendar wins; its own title is only tried
+	// when no name matches
+	names := []func(Calendar) string{
+		Calendar.Name,
+		func(cal Calendar) string {
+			if cal.SummaryOverride == "" {
+				return ""
+			}
+			return cal.Summary
+		},
+	}
+	for _, name := range names {
+		for _, match := range stages {
+			var matches []Calendar
+			for _, cal := range calendars {
+				if n := normalizeName(name(cal)); n != "" && match(n) {
+					matches = append(matches, cal)
+				}
+			}
+			if len(matches) > 0 {
+				return matches
+			}
+		}
+	}
+	return nil
+}
+
+// normalizeName lowercases a name and reduces it to space-separated words of letters and digits
+func normalizeName(s string) string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, " ")
+}
+
+// containsWords reports whether every word of want is a prefix of some word of name
+func containsWords(name, want string) bool {
+	nameWords := strings.Fields(name)
+	for _, w := range strings.Fields(want) {
+		found := false
+		for _, nw := range nameWords {
+			if strings.HasPrefix(nw, w) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
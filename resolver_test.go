@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func newResolverTestProvider() *fakeProvider {
+	return &fakeProvider{
+		accounts: []string{"work"},
+		calendars: map[string][]Calendar{
+			"work": {
+				{ID: "me@work.example.com", Summary: "me@work.example.com", Primary: true, Account: "work"},
+				{ID: "team@group.calendar.google.com", Summary: "Team", Account: "work"},
+				{ID: "team-social@group.calendar.google.com", Summary: "Team Social", Account: "work"},
+				{ID: "oncall@group.calendar.google.com", Summary: "On-call rotation", Account: "work"},
+				{ID: "fr.french#holiday@group.v.calendar.google.com", Summary: "Holidays in France", Account: "work"},
+				{ID: "c_42@group.calendar.google.com", Summary: "Engineering - Platform squad", SummaryOverride: "Squad", Account: "work"},
+			},
+		},
+		aliases: map[string]map[string]string{
+			"work": {
+				"pager":  "oncall@group.calendar.google.com",
+				"social": "team@group.calendar.google.com",
+				"Social": "team-social@group.calendar.google.com",
+			},
+		},
+	}
+}
+
+// TestCalendarResolver verifies the accepted kinds of calendar references
+func TestCalendarResolver(t *testing.T) {
+	tests := []struct {
+		ref  string
+		want string
+	}{
+		{ref: "", want: ""},
+		{ref: "primary", want: "primary"},
+		{ref: "someone@example.com", want: "someone@example.com"},
+		{ref: "pager", want: "oncall@group.calendar.google.com"},
+		{ref: "PAGER", want: "oncall@group.calendar.google.com"},
+		{ref: "social", want: "team@group.calendar.google.com"},
+		{ref: "Social", want: "team-social@group.calendar.google.com"},
+		{ref: "SOCIAL", want: "team-social@group.calendar.google.com"},
+		{ref: "team", want: "team@group.calendar.google.com"},
+		{ref: "  Team ", want: "team@group.calendar.google.com"},
+		{ref: "team social", want: "team-social@group.calendar.google.com"},
+		{ref: "on call", want: "oncall@group.calendar.google.com"},
+		{ref: "holidays", want: "fr.french#holiday@group.v.calendar.google.com"},
+		{ref: "Holidays in Frnace", want: "fr.french#holiday@group.v.calendar.google.com"},
+		{ref: "squad", want: "c_42@group.calendar.google.com"},
+		{ref: "platform", want: "c_42@group.calendar.google.com"},
+	}
+
+	resolver := newCalendarResolver(newResolverTestProvider())
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			got, err := resolver.resolve(context.Background(), "work", tt.ref)
+			if err != nil {
+				t.Fatalf("resolve(%q) error: %v", tt.ref, err)
+			}
+			if got != tt.want {
+				t.Errorf("resolve(%q) = %q, want %q", tt.ref, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestCalendarResolverErrors verifies ambiguous and unknown names are reported with candidates
+func TestCalendarResolverErrors(t *testing.T) {
+	resolver := newCalendarResolver(newResolverTestProvider())
+
+	_, err := resolver.resolve(context.Background(), "work", "tea")
+	var ambiguous *AmbiguousCalendarError
+	if !errors.As(err, &ambiguous) {
+		t.Fatalf("Expected AmbiguousCalendarError, got %v", err)
+	}
+	for _, want := range []string{"Team (team@group.calendar.google.com)", "Team Social (team-social@group.calendar.google.com)"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected candidate %q in %q", want, err.Error())
+		}
+	}
+
+	_, err = resolver.resolve(context.Background(), "work", "birthdays")
+	if !isCalendarNotFound(err) {
+		t.Fatalf("Expected CalendarNotFoundError, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "available: me@work.example.com, Team, Team Social") {
+		t.Errorf("Expected available calendars in %q", err.Error())
+	}
+}
+
+// TestEditDistance checks the Levenshtein distance used for fuzzy matching
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"team", "team", 0},
+		{"team", "teams", 1},
+		{"france", "frnace", 2},
+		{"", "abc", 3},
+		{"équipe", "equipe", 1},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"strings"
@@ -18,79 +19,601 @@ type ListAccountsOutput struct {
 }
 
 type ListCalendarsInput struct {
-	Account string `json:"account,omitempty" jsonschema:"description:Account name (optional - if empty lists from all accounts)"`
+	Account        string `json:"account,omitempty" jsonschema:"description:Account name (optional - if empty lists from all accounts)"`
+	MaxResults     int    `json:"max_results,omitempty" jsonschema:"description:Maximum number of calendars to return across all accounts (default 250 max 1000)"`
+	SelectedOnly   bool   `json:"selected_only,omitempty" jsonschema:"description:Only return the calendars shown in the Google Calendar UI"`
+	MinAccessRole  string `json:"min_access_role,omitempty" jsonschema:"description:Only return calendars the user has at least this access to: freeBusyReader, reader, writer or owner (optional)"`
+	IncludeHidden  bool   `json:"include_hidden,omitempty" jsonschema:"description:Also return calendars hidden from the calendar list"`
+	IncludeDeleted bool   `json:"include_deleted,omitempty" jsonschema:"description:Also return calendars removed from the calendar list"`
+	Cursor         string `json:"cursor,omitempty" jsonschema:"description:next_cursor returned by a previous call, to get the next calendars; the other arguments must be the same as in that call"`
 }
 
+// Calendar is an entry of an account's calendar list. Summary is the
+// calendar's own title and SummaryOverride the name the user gave it, which
+// takes precedence in display.
 type Calendar struct {
-	ID          string `json:"id"`
-	Summary     string `json:"summary"`
-	Description string `json:"description,omitempty"`
-	Primary     bool   `json:"primary,omitempty"`
-	Account     string `json:"account"`
+	ID              string `json:"id"`
+	Summary         string `json:"summary"`
+	SummaryOverride string `json:"summary_override,omitempty"`
+	Description     string `json:"description,omitempty"`
+	Primary         bool   `json:"primary,omitempty"`
+	Selected        bool   `json:"selected,omitempty"`
+	Hidden          bool   `json:"hidden,omitempty"`
+	Deleted         bool   `json:"deleted,omitempty"`
+	// AccessRole is freeBusyReader, reader, writer or owner; events can only
+	// be listed from reader calendars and up
+	AccessRole      string `json:"access_role,omitempty"`
+	TimeZone        string `json:"time_zone,omitempty"`
+	ColorID         string `json:"color_id,omitempty"`
+	ColorName       string `json:"color_name,omitempty"`
+	BackgroundColor string `json:"background_color,omitempty"`
+	ForegroundColor string `json:"foreground_color,omitempty"`
+	Account         string `json:"account"`
+}
+
+// Name returns the name the user sees for the calendar
+func (c Calendar) Name() string {
+	return cmp.Or(c.SummaryOverride, c.Summary)
+}
+
+// AccountError reports an account, or a single calendar of an account, that could not be queried
+type AccountError struct {
+	Account    string `json:"account"`
+	CalendarID string `json:"calendar_id,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+	Error      string `json:"error"`
 }
 
 type ListCalendarsOutput struct {
 	Calendars []Calendar `json:"calendars"`
+	Truncated bool       `json:"truncated,omitempty"`
+	// NextCursor continues a truncated listing when passed back as cursor
+	NextCursor string         `json:"next_cursor,omitempty"`
+	Errors     []AccountError `json:"errors,omitempty"`
 }
 
 type ListEventsInput struct {
-	Account    string `json:"account,omitempty" jsonschema:"description:Account name (optional - if empty queries all accounts)"`
-	CalendarID string `json:"calendar_id,omitempty" jsonschema:"description:Calendar ID (optional - if empty uses primary calendar)"`
-	TimeMin    string `json:"time_min,omitempty" jsonschema:"description:Start of time range (RFC3339 format). Defaults to now."`
-	TimeMax    string `json:"time_max,omitempty" jsonschema:"description:End of time range (RFC3339 format). Defaults to 7 days from now."`
-	MaxResults int    `json:"max_results,omitempty" jsonschema:"description:Maximum number of events to return (default 50 max 250)"`
-	Query      string `json:"query,omitempty" jsonschema:"description:Free text search query"`
+	Account           string   `json:"account,omitempty" jsonschema:"description:Account name (optional - if empty queries all accounts)"`
+	CalendarID        string   `json:"calendar_id,omitempty" jsonschema:"description:Calendar ID, alias or name (optional - if empty uses primary calendar)"`
+	CalendarIDs       []string `json:"calendar_ids,omitempty" jsonschema:"description:List of calendar IDs, aliases or names to query together with calendar_id (optional)"`
+	AllCalendars      bool     `json:"all_calendars,omitempty" jsonschema:"description:Query every calendar selected in Google Calendar instead of calendar_id/calendar_ids"`
+	TimeMin           string   `json:"time_min,omitempty" jsonschema:"description:Start of time range: RFC3339, a date or an expression such as today, tomorrow, this afternoon, friday, next week, 2026-W07 or -2h. Defaults to now."`
+	TimeMax           string   `json:"time_max,omitempty" jsonschema:"description:End of time range: RFC3339, a date or an expression such as tomorrow, next week or +3d (durations count from time_min). Defaults to the end of the time_min period or 7 days later."`
+	MaxResults        int      `json:"max_results,omitempty" jsonschema:"description:Maximum number of events to return across all accounts (default 50 max 250)"`
+	Query             string   `json:"query,omitempty" jsonschema:"description:Free text search query"`
+	SeriesMasters     bool     `json:"series_masters,omitempty" jsonschema:"description:List each recurring series once as its master event with recurrence rules instead of expanding it into instances (default false)"`
+	TimeZone          string   `json:"time_zone,omitempty" jsonschema:"description:IANA time zone for the output such as Europe/Paris (optional - defaults to the account's configured time zone)"`
+	AcceptedOnly      bool     `json:"accepted_only,omitempty" jsonschema:"description:Only return events the user accepted or was not invited to, such as their own events"`
+	ExcludeDeclined   bool     `json:"exclude_declined,omitempty" jsonschema:"description:Leave out events the user declined"`
+	EventTypes        []string `json:"event_types,omitempty" jsonschema:"description:Only return events of these types: default, birthday, focusTime, fromGmail, outOfOffice or workingLocation (optional - if empty returns every type)"`
+	ExcludeEventTypes []string `json:"exclude_event_types,omitempty" jsonschema:"description:Leave out events of these types, such as workingLocation"`
+	Detail            string   `json:"detail,omitempty" jsonschema:"description:compact (default) or full - full also returns reminders, attachments, visibility, creator, timestamps, source and guest permissions"`
+	Cursor            string   `json:"cursor,omitempty" jsonschema:"description:next_cursor returned by a previous call, to get the next events; the other arguments must be the same as in that call"`
+	Fields            []string `json:"fields,omitempty" jsonschema:"description:Event fields to return: a preset (minimal, standard or full) and/or fields among description, location, attendees, organizer, response_status, conference, transparency, ical_uid, color, event_type, recurrence and details (optional - defaults to standard, or full with detail full). The ID, summary, times, status and calendar are always returned."`
 }
 
 type Event struct {
-	ID          string    `json:"id"`
-	Summary     string    `json:"summary"`
-	Description string    `json:"description,omitempty"`
-	Location    string    `json:"location,omitempty"`
-	Start       time.Time `json:"start"`
-	End         time.Time `json:"end"`
-	AllDay      bool      `json:"all_day"`
-	Attendees   []string  `json:"attendees,omitempty"`
-	Organizer   string    `json:"organizer,omitempty"`
-	Status      string    `json:"status"`
-	HtmlLink    string    `json:"html_link"`
-	Account     string    `json:"account"`
-	CalendarID  string    `json:"calendar_id"`
+	ID          string     `json:"id"`
+	Summary     string     `json:"summary"`
+	Description string     `json:"description,omitempty"`
+	Location    string     `json:"location,omitempty"`
+	Start       time.Time  `json:"start"`
+	End         time.Time  `json:"end"`
+	AllDay      bool       `json:"all_day"`
+	Attendees   []Attendee `json:"attendees,omitempty"`
+	Organizer   string     `json:"organizer,omitempty"`
+	// ResponseStatus is the user's own answer to the invitation, empty when
+	// the user is not an attendee
+	ResponseStatus string `json:"response_status,omitempty"`
+	// HangoutLink is the Google Meet link of the event
+	HangoutLink string `json:"hangout_link,omitempty"`
+	// Conference tells how to join the meeting, and MeetingURL is its video link
+	Conference   *Conference `json:"conference,omitempty"`
+	MeetingURL   string      `json:"meeting_url,omitempty"`
+	Status       string      `json:"status"`
+	HtmlLink     string      `json:"html_link"`
+	Account      string      `json:"account"`
+	CalendarID   string      `json:"calendar_id"`
+	CalendarName string      `json:"calendar_name,omitempty"`
+
+	// Transparency is "transparent" for events that do not block time, and
+	// ICalUID identifies the same meeting across calendars and accounts
+	Transparency string `json:"transparency,omitempty"`
+	ICalUID      string `json:"ical_uid,omitempty"`
+
+	// ColorID is the event's own color, which overrides the calendar's, and
+	// Color resolves it to its name and hex values
+	ColorID string `json:"color_id,omitempty"`
+	Color   *Color `json:"color,omitempty"`
+
+	// Details are only returned by get_event, and by list_events when asked for
+	Details *EventDetails `json:"details,omitempty"`
+
+	// EventType is default, birthday, focusTime, fromGmail, outOfOffice or
+	// workingLocation; the properties of the special types are set with it
+	EventType       string            `json:"event_type,omitempty"`
+	WorkingLocation *WorkingLocation  `json:"working_location,omitempty"`
+	OutOfOffice     *StatusProperties `json:"out_of_office,omitempty"`
+	FocusTime       *StatusProperties `json:"focus_time,omitempty"`
+
+	// TimeZone is the time zone the event was scheduled in; Start and End
+	// are expressed in the user's time zone
+	TimeZone string `json:"time_zone,omitempty"`
+
+	// Recurrence holds the RRULE, EXRULE, RDATE and EXDATE lines of a series master
+	Recurrence []string `json:"recurrence,omitempty"`
+	// RecurringEventID is the ID of the series master of an instance
+	RecurringEventID string `json:"recurring_event_id,omitempty"`
+	// OriginalStartTime is the start an instance has in the series, before any rescheduling
+	OriginalStartTime *time.Time `json:"original_start_time,omitempty"`
+}
+
+// WorkingLocation is where a working location event says the user works:
+// Type is homeOffice, officeLocation or customLocation
+type WorkingLocation struct {
+	Type           string `json:"type"`
+	Label          string `json:"label,omitempty"`
+	BuildingID     string `json:"building_id,omitempty"`
+	FloorID        string `json:"floor_id,omitempty"`
+	FloorSectionID string `json:"floor_section_id,omitempty"`
+	DeskID         string `json:"desk_id,omitempty"`
+}
+
+// EventDetails are the less used properties of an event. Visibility is
+// default, public, private or confidential.
+type EventDetails struct {
+	Visibility  string       `json:"visibility,omitempty"`
+	Reminders   *Reminders   `json:"reminders,omitempty"`
+	Attachments []Attachment `json:"attachments,omitempty"`
+	Creator     string       `json:"creator,omitempty"`
+	Created     time.Time    `json:"created,omitzero"`
+	Updated     time.Time    `json:"updated,omitzero"`
+	// Sequence is the iCalendar revision of the event, bumped on each reschedule
+	Sequence int          `json:"sequence,omitempty"`
+	Source   *EventSource `json:"source,omitempty"`
+
+	GuestsCanInviteOthers   bool `json:"guests_can_invite_others"`
+	GuestsCanModify         bool `json:"guests_can_modify"`
+	GuestsCanSeeOtherGuests bool `json:"guests_can_see_other_guests"`
+}
+
+// Reminders tells whether the calendar's default reminders apply to an event,
+// or which reminders replace them
+type Reminders struct {
+	UseDefault bool       `json:"use_default"`
+	Overrides  []Reminder `json:"overrides,omitempty"`
+}
+
+// Reminder is a popup or email notification sent Minutes before an event
+type Reminder struct {
+	Method  string `json:"method"`
+	Minutes int    `json:"minutes"`
+}
+
+// Attachment is a file, usually from Google Drive, attached to an event
+type Attachment struct {
+	Title    string `json:"title"`
+	FileURL  string `json:"file_url"`
+	MimeType string `json:"mime_type,omitempty"`
+	FileID   string `json:"file_id,omitempty"`
+}
+
+// EventSource is the web page or email an event was created from
+type EventSource struct {
+	Title string `json:"title,omitempty"`
+	URL   string `json:"url"`
+}
+
+// StatusProperties are the settings of out of office and focus time events.
+// AutoDeclineMode tells which invitations are declined while they last.
+type StatusProperties struct {
+	AutoDeclineMode string `json:"auto_decline_mode,omitempty"`
+	DeclineMessage  string `json:"decline_message,omitempty"`
+	ChatStatus      string `json:"chat_status,omitempty"`
+}
+
+// Attendee is a guest of an event
+type Attendee struct {
+	Email            string `json:"email"`
+	DisplayName      string `json:"display_name,omitempty"`
+	ResponseStatus   string `json:"response_status"`
+	Optional         bool   `json:"optional,omitempty"`
+	Organizer        bool   `json:"organizer,omitempty"`
+	Self             bool   `json:"self,omitempty"`
+	Resource         bool   `json:"resource,omitempty"`
+	AdditionalGuests int    `json:"additional_guests,omitempty"`
+	Comment          string `json:"comment,omitempty"`
+}
+
+// Conference describes how to join an online meeting. Source tells where the
+// data comes from: "conference_data", "hangout_link" or "extracted" from the
+// location and description.
+type Conference struct {
+	Solution    string       `json:"solution,omitempty"`
+	ID          string       `json:"id,omitempty"`
+	EntryPoints []EntryPoint `json:"entry_points"`
+	Notes       string       `json:"notes,omitempty"`
+	Source      string       `json:"source"`
+}
+
+// EntryPoint is a way to join a conference: "video", "phone", "sip" or "more"
+type EntryPoint struct {
+	Type       string `json:"type"`
+	URI        string `json:"uri"`
+	Label      string `json:"label,omitempty"`
+	PIN        string `json:"pin,omitempty"`
+	Passcode   string `json:"passcode,omitempty"`
+	RegionCode string `json:"region_code,omitempty"`
 }
 
 type ListEventsOutput struct {
-	Events []Event `json:"events"`
+	Events    []Event   `json:"events"`
+	TimeMin   time.Time `json:"time_min,omitzero"`
+	TimeMax   time.Time `json:"time_max,omitzero"`
+	TimeZone  string    `json:"time_zone,omitempty"`
+	Truncated bool      `json:"truncated,omitempty"`
+	// NextCursor continues a truncated listing when passed back as cursor
+	NextCursor string         `json:"next_cursor,omitempty"`
+	Errors     []AccountError `json:"errors,omitempty"`
 }
 
 type GetEventInput struct {
-	Account    string `json:"account" jsonschema:"description:Account name,required"`
-	CalendarID string `json:"calendar_id" jsonschema:"description:Calendar ID,required"`
-	EventID    string `json:"event_id" jsonschema:"description:Event ID,required"`
+	Account    string   `json:"account" jsonschema:"description:Account name,required"`
+	CalendarID string   `json:"calendar_id" jsonschema:"description:Calendar ID, alias or name,required"`
+	EventID    string   `json:"event_id" jsonschema:"description:Event ID,required"`
+	TimeZone   string   `json:"time_zone,omitempty" jsonschema:"description:IANA time zone for the output such as Europe/Paris (optional - defaults to the account's configured time zone)"`
+	Fields     []string `json:"fields,omitempty" jsonschema:"description:Event fields to return: a preset (minimal, standard or full) and/or field names as in list_events (optional - defaults to full)"`
 }
 
 type GetEventOutput struct {
 	Event Event `json:"event"`
 }
 
+type ListEventInstancesInput struct {
+	Account    string `json:"account" jsonschema:"description:Account name,required"`
+	CalendarID string `json:"calendar_id" jsonschema:"description:Calendar ID, alias or name,required"`
+	EventID    string `json:"event_id" jsonschema:"description:ID of the recurring event or of one of its instances,required"`
+	TimeMin    string `json:"time_min,omitempty" jsonschema:"description:Start of time range: RFC3339, a date or an expression such as today, tomorrow, this afternoon, friday, next week, 2026-W07 or -2h. Defaults to now."`
+	TimeMax    string `json:"time_max,omitempty" jsonschema:"description:End of time range: RFC3339, a date or an expression such as tomorrow, next week or +3d (durations count from time_min). Defaults to the end of the time_min period or of the series."`
+	MaxResults int    `json:"max_results,omitempty" jsonschema:"description:Maximum number of instances to return (default 50 max 250)"`
+	TimeZone   string `json:"time_zone,omitempty" jsonschema:"description:IANA time zone for the output such as Europe/Paris (optional - defaults to the account's configured time zone)"`
+	Cursor     string `json:"cursor,omitempty" jsonschema:"description:next_cursor returned by a previous call, to get the next instances; the other arguments must be the same as in that call"`
+}
+
+type ListEventInstancesOutput struct {
+	Series    Event     `json:"series"`
+	Instances []Event   `json:"instances"`
+	TimeMin   time.Time `json:"time_min,omitzero"`
+	TimeMax   time.Time `json:"time_max,omitzero"`
+	TimeZone  string    `json:"time_zone,omitempty"`
+	Truncated bool      `json:"truncated,omitempty"`
+	// NextCursor continues a truncated listing when passed back as cursor
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
 type CheckAvailabilityInput struct {
-	Account    string   `json:"account,omitempty" jsonschema:"description:Account name (optional - if empty checks all accounts)"`
-	Calendars  []string `json:"calendars,omitempty" jsonschema:"description:List of calendar IDs to check (optional - if empty uses primary)"`
-	TimeMin    string   `json:"time_min" jsonschema:"description:Start of time range (RFC3339 format),required"`
-	TimeMax    string   `json:"time_max" jsonschema:"description:End of time range (RFC3339 format),required"`
+	Account   string   `json:"account,omitempty" jsonschema:"description:Account name (optional - if empty checks all accounts)"`
+	Calendars []string `json:"calendars,omitempty" jsonschema:"description:List of calendar IDs, aliases or names to check (optional - if empty uses primary)"`
+	TimeMin   string   `json:"time_min" jsonschema:"description:Start of time range: RFC3339, a date or an expression such as today, tomorrow, this afternoon, friday, next week, 2026-W07 or -2h,required"`
+	TimeMax   string   `json:"time_max" jsonschema:"description:End of time range: RFC3339, a date or an expression such as tomorrow, next week or +3d (durations count from time_min),required"`
+	Merge     bool     `json:"merge,omitempty" jsonschema:"description:Also return a merged non-overlapping busy timeline across all accounts and calendars (default false)"`
+	TimeZone  string   `json:"time_zone,omitempty" jsonschema:"description:IANA time zone for the output such as Europe/Paris (optional - defaults to the account's configured time zone)"`
 }
 
 type BusyPeriod struct {
+	Start      time.Time `json:"start"`
+	End        time.Time `json:"end"`
+	Account    string    `json:"account"`
+	CalendarID string    `json:"calendar_id,omitempty"`
+}
+
+// BusyBlock is a span of the merged busy timeline
+type BusyBlock struct {
+	Start   time.Time    `json:"start"`
+	End     time.Time    `json:"end"`
+	Sources []BusySource `json:"sources"`
+}
+
+// BusySource identifies a calendar that contributed to a busy block
+type BusySource struct {
+	Account    string `json:"account"`
+	CalendarID string `json:"calendar_id,omitempty"`
+}
+
+type CheckAvailabilityOutput struct {
+	BusyPeriods []BusyPeriod   `json:"busy_periods"`
+	BusyBlocks  []BusyBlock    `json:"busy_blocks,omitempty"`
+	TimeMin     time.Time      `json:"time_min,omitzero"`
+	TimeMax     time.Time      `json:"time_max,omitzero"`
+	TimeZone    string         `json:"time_zone,omitempty"`
+	Errors      []AccountError `json:"errors,omitempty"`
+}
+
+type FindFreeSlotsInput struct {
+	Accounts        []string `json:"accounts,omitempty" jsonschema:"description:Account names to check (optional - if empty checks all accounts)"`
+	Calendars       []string `json:"calendars,omitempty" jsonschema:"description:List of calendar IDs, aliases or names to check (optional - if empty uses primary)"`
+	TimeMin         string   `json:"time_min" jsonschema:"description:Start of time range: RFC3339, a date or an expression such as today, tomorrow, this afternoon, friday, next week, 2026-W07 or -2h,required"`
+	TimeMax         string   `json:"time_max" jsonschema:"description:End of time range: RFC3339, a date or an expression such as tomorrow, next week or +3d (durations count from time_min),required"`
+	DurationMinutes int      `json:"duration_minutes,omitempty" jsonschema:"description:Minimum length of a free slot in minutes (default 30)"`
+	BufferMinutes   int      `json:"buffer_minutes,omitempty" jsonschema:"description:Free time to keep before and after busy periods in minutes (default 0)"`
+	WorkdayStart    string   `json:"workday_start,omitempty" jsonschema:"description:Start of working hours as HH:MM (default 09:00)"`
+	WorkdayEnd      string   `json:"workday_end,omitempty" jsonschema:"description:End of working hours as HH:MM (default 17:00)"`
+	IncludeWeekends bool     `json:"include_weekends,omitempty" jsonschema:"description:Also look for slots on Saturdays and Sundays"`
+	TimeZone        string   `json:"time_zone,omitempty" jsonschema:"description:IANA time zone for working hours and results such as Europe/Paris (optional - defaults to the account's configured time zone)"`
+	MaxResults      int      `json:"max_results,omitempty" jsonschema:"description:Maximum number of slots to return (default 10 max 50)"`
+}
+
+type FreeSlot struct {
+	Start           time.Time `json:"start"`
+	End             time.Time `json:"end"`
+	DurationMinutes int       `json:"duration_minutes"`
+	Rank            int       `json:"rank"`
+}
+
+type FindFreeSlotsOutput struct {
+	Slots    []FreeSlot     `json:"slots"`
+	TimeMin  time.Time      `json:"time_min,omitzero"`
+	TimeMax  time.Time      `json:"time_max,omitzero"`
+	TimeZone string         `json:"time_zone"`
+	Errors   []AccountError `json:"errors,omitempty"`
+}
+
+type NextEventInput struct {
+	Account         string `json:"account,omitempty" jsonschema:"description:Account name (optional - if empty checks all accounts)"`
+	WithinDays      int    `json:"within_days,omitempty" jsonschema:"description:How many days ahead to look for the next event (default 7 max 31)"`
+	IncludeAllDay   bool   `json:"include_all_day,omitempty" jsonschema:"description:Also consider all-day events (default false)"`
+	IncludeDeclined bool   `json:"include_declined,omitempty" jsonschema:"description:Also consider events the user declined (default false)"`
+	TimeZone        string `json:"time_zone,omitempty" jsonschema:"description:IANA time zone for the output such as Europe/Paris (optional - defaults to the account's configured time zone)"`
+}
+
+type NextEventOutput struct {
+	Now               time.Time      `json:"now"`
+	Current           []Event        `json:"current"`
+	Next              *Event         `json:"next,omitempty"`
+	StartsIn          string         `json:"starts_in,omitempty"`
+	MinutesUntilStart int            `json:"minutes_until_start,omitempty"`
+	WithinDays        int            `json:"within_days"`
+	TimeZone          string         `json:"time_zone"`
+	Errors            []AccountError `json:"errors,omitempty"`
+}
+
+type AgendaInput struct {
+	Account         string   `json:"account,omitempty" jsonschema:"description:Account name (optional - if empty queries all accounts)"`
+	CalendarID      string   `json:"calendar_id,omitempty" jsonschema:"description:Calendar ID, alias or name (optional - if empty uses primary calendar)"`
+	CalendarIDs     []string `json:"calendar_ids,omitempty" jsonschema:"description:List of calendar IDs, aliases or names to query together with calendar_id (optional)"`
+	AllCalendars    bool     `json:"all_calendars,omitempty" jsonschema:"description:Query every calendar selected in Google Calendar instead of calendar_id/calendar_ids"`
+	View            string   `json:"view,omitempty" jsonschema:"description:day, work_week (Monday to Friday) or week (seven days from the first day of the week in the account's settings - Monday by default) (default day)"`
+	Date            string   `json:"date,omitempty" jsonschema:"description:A day in the period to show: a date such as 2026-02-03 or an expression such as today, tomorrow, friday or next week (default today)"`
+	IncludeDeclined bool     `json:"include_declined,omitempty" jsonschema:"description:Also show events the user declined (default false)"`
+	TimeZone        string   `json:"time_zone,omitempty" jsonschema:"description:IANA time zone for the days and times such as Europe/Paris (optional - defaults to the account's configured time zone)"`
+}
+
+// AgendaDay holds the events of one day of an agenda
+type AgendaDay struct {
+	Date    string       `json:"date"`
+	Weekday string       `json:"weekday"`
+	Items   []AgendaItem `json:"items"`
+}
+
+// AgendaItem is an event as it appears on one day of an agenda. Events
+// spanning several days appear on each of them, as day Day of Days.
+type AgendaItem struct {
+	Event    Event      `json:"event"`
+	Day      int        `json:"day,omitempty"`
+	Days     int        `json:"days,omitempty"`
+	Overlaps []EventRef `json:"overlaps,omitempty"`
+}
+
+// EventRef identifies an event by its account, calendar and ID
+type EventRef struct {
+	Account    string `json:"account"`
+	CalendarID string `json:"calendar_id"`
+	EventID    string `json:"event_id"`
+	Summary    string `json:"summary,omitempty"`
+}
+
+type AgendaOutput struct {
+	View      string         `json:"view"`
+	TimeMin   time.Time      `json:"time_min"`
+	TimeMax   time.Time      `json:"time_max"`
+	TimeZone  string         `json:"time_zone"`
+	Days      []AgendaDay    `json:"days"`
+	Truncated bool           `json:"truncated,omitempty"`
+	Errors    []AccountError `json:"errors,omitempty"`
+}
+
+type FindConflictsInput struct {
+	Account       string   `json:"account,omitempty" jsonschema:"description:Account name (optional - if empty checks all accounts)"`
+	Calendars     []string `json:"calendars,omitempty" jsonschema:"description:List of calendar IDs, aliases or names to check (optional - if empty checks every selected calendar)"`
+	TimeMin       string   `json:"time_min,omitempty" jsonschema:"description:Start of time range: RFC3339, a date or an expression such as today, tomorrow, this afternoon, friday, next week, 2026-W07 or -2h. Defaults to now."`
+	TimeMax       string   `json:"time_max,omitempty" jsonschema:"description:End of time range: RFC3339, a date or an expression such as tomorrow, next week or +3d (durations count from time_min). Defaults to the end of the time_min period or 7 days later."`
+	BufferMinutes int      `json:"buffer_minutes,omitempty" jsonschema:"description:Events separated by less than this many minutes are reported as back-to-back (default 0 - only events ending right as the next starts)"`
+	IncludeAllDay bool     `json:"include_all_day,omitempty" jsonschema:"description:Also check all-day events that block time (default false)"`
+	TimeZone      string   `json:"time_zone,omitempty" jsonschema:"description:IANA time zone for the output such as Europe/Paris (optional - defaults to the account's configured time zone)"`
+}
+
+// Conflict is a pair of events that overlap ("overlap") or follow each other
+// without a break ("back_to_back"). Start and End delimit the overlap or the
+// gap between the events.
+type Conflict struct {
+	Kind    string    `json:"kind"`
 	Start   time.Time `json:"start"`
 	End     time.Time `json:"end"`
-	Account string    `json:"account"`
+	Minutes int       `json:"minutes"`
+	Events  []Event   `json:"events"`
 }
 
-type CheckAvailabilityOutput struct {
-	BusyPeriods []BusyPeriod `json:"busy_periods"`
+type FindConflictsOutput struct {
+	Conflicts []Conflict     `json:"conflicts"`
+	Checked   int            `json:"checked"`
+	TimeMin   time.Time      `json:"time_min,omitzero"`
+	TimeMax   time.Time      `json:"time_max,omitzero"`
+	TimeZone  string         `json:"time_zone"`
+	Truncated bool           `json:"truncated,omitempty"`
+	Errors    []AccountError `json:"errors,omitempty"`
+}
+
+type CalendarStatsInput struct {
+	Account          string   `json:"account,omitempty" jsonschema:"description:Account name (optional - if empty covers all accounts)"`
+	Calendars        []string `json:"calendars,omitempty" jsonschema:"description:List of calendar IDs, aliases or names to cover (optional - if empty covers every selected calendar)"`
+	TimeMin          string   `json:"time_min,omitempty" jsonschema:"description:Start of time range: RFC3339, a date or an expression such as today, this week, last week, this month or 2026-W07. Defaults to this week."`
+	TimeMax          string   `json:"time_max,omitempty" jsonschema:"description:End of time range: RFC3339, a date or an expression such as friday, next week or +3d (durations count from time_min). Defaults to the end of the time_min period or 7 days later."`
+	WorkdayStart     string   `json:"workday_start,omitempty" jsonschema:"description:Start of working hours as HH:MM (default 09:00)"`
+	WorkdayEnd       string   `json:"workday_end,omitempty" jsonschema:"description:End of working hours as HH:MM (default 17:00)"`
+	IncludeWeekends  bool     `json:"include_weekends,omitempty" jsonschema:"description:Count Saturdays and Sundays as working days"`
+	MinFocusMinutes  int      `json:"min_focus_minutes,omitempty" jsonschema:"description:Shortest free block counted as focus time in minutes (default 30)"`
+	MaxCollaborators int      `json:"max_collaborators,omitempty" jsonschema:"description:Number of top collaborators to return (default 5 max 50)"`
+	TimeZone         string   `json:"time_zone,omitempty" jsonschema:"description:IANA time zone for days and working hours such as Europe/Paris (optional - defaults to the account's configured time zone)"`
+}
+
+// DayStats is the meeting load of one day
+type DayStats struct {
+	Date               string `json:"date"`
+	Weekday            string `json:"weekday"`
+	Meetings           int    `json:"meetings"`
+	MeetingMinutes     int    `json:"meeting_minutes"`
+	FocusMinutes       int    `json:"focus_minutes"`
+	LongestFreeMinutes int    `json:"longest_free_minutes"`
+}
+
+// WeekStats is the meeting load of one ISO week, such as 2026-W06
+type WeekStats struct {
+	Week           string `json:"week"`
+	Meetings       int    `json:"meetings"`
+	MeetingMinutes int    `json:"meeting_minutes"`
+	FocusMinutes   int    `json:"focus_minutes"`
+}
+
+// CollaboratorStats is the time spent in meetings with someone
+type CollaboratorStats struct {
+	Email       string `json:"email"`
+	DisplayName string `json:"display_name,omitempty"`
+	Meetings    int    `json:"meetings"`
+	Minutes     int    `json:"minutes"`
+}
+
+// MeetingLoad is the time spent in the meetings of an account or calendar
+type MeetingLoad struct {
+	Account      string `json:"account"`
+	CalendarID   string `json:"calendar_id,omitempty"`
+	CalendarName string `json:"calendar_name,omitempty"`
+	Meetings     int    `json:"meetings"`
+	Minutes      int    `json:"minutes"`
+}
+
+// FreeBlock is a meeting-free span of working hours
+type FreeBlock struct {
+	Start           time.Time `json:"start"`
+	End             time.Time `json:"end"`
+	DurationMinutes int       `json:"duration_minutes"`
+}
+
+type CalendarStatsOutput struct {
+	TimeMin          time.Time           `json:"time_min"`
+	TimeMax          time.Time           `json:"time_max"`
+	TimeZone         string              `json:"time_zone"`
+	WorkingHours     string              `json:"working_hours"`
+	Meetings         int                 `json:"meetings"`
+	OneOnOneMeetings int                 `json:"one_on_one_meetings"`
+	GroupMeetings    int                 `json:"group_meetings"`
+	SoloEvents       int                 `json:"solo_events"`
+	MeetingMinutes   int                 `json:"meeting_minutes"`
+	FocusMinutes     int                 `json:"focus_minutes"`
+	LongestFreeBlock *FreeBlock          `json:"longest_free_block,omitempty"`
+	Days             []DayStats          `json:"days"`
+	Weeks            []WeekStats         `json:"weeks"`
+	Collaborators    []CollaboratorStats `json:"collaborators"`
+	Calendars        []MeetingLoad       `json:"calendars"`
+	Accounts         []MeetingLoad       `json:"accounts"`
+	Truncated        bool                `json:"truncated,omitempty"`
+	Errors           []AccountError      `json:"errors,omitempty"`
+}
+
+type WorkingLocationInput struct {
+	Account  string `json:"account,omitempty" jsonschema:"description:Account name (optional - if empty checks all accounts)"`
+	Date     string `json:"date,omitempty" jsonschema:"description:Day or period to check: a date such as 2026-02-05 or an expression such as today, tomorrow, thursday or next week (default today)"`
+	TimeZone string `json:"time_zone,omitempty" jsonschema:"description:IANA time zone for the days such as Europe/Paris (optional - defaults to the account's configured time zone)"`
+}
+
+// WorkingLocationEntry is a working location set for a day. Start and End
+// are only set for locations covering part of the day.
+type WorkingLocationEntry struct {
+	Account  string          `json:"account"`
+	Location WorkingLocation `json:"location"`
+	AllDay   bool            `json:"all_day"`
+	Start    time.Time       `json:"start,omitzero"`
+	End      time.Time       `json:"end,omitzero"`
+}
+
+// WorkingDay tells where the user works on a day
+type WorkingDay struct {
+	Date        string                 `json:"date"`
+	Weekday     string                 `json:"weekday"`
+	Locations   []WorkingLocationEntry `json:"locations"`
+	OutOfOffice bool                   `json:"out_of_office,omitempty"`
+}
+
+type WorkingLocationOutput struct {
+	Days     []WorkingDay   `json:"days"`
+	TimeMin  time.Time      `json:"time_min"`
+	TimeMax  time.Time      `json:"time_max"`
+	TimeZone string         `json:"time_zone"`
+	Errors   []AccountError `json:"errors,omitempty"`
+}
+
+type GetSettingsInput struct {
+	Account string `json:"account,omitempty" jsonschema:"description:Account name (optional - if empty returns the settings of all accounts)"`
+}
+
+// AccountSettings holds the Google Calendar settings of an account: the
+// well-known ones parsed, and every setting as returned by the API
+type AccountSettings struct {
+	Account            string            `json:"account"`
+	TimeZone           string            `json:"time_zone,omitempty"`
+	WeekStart          string            `json:"week_start,omitempty"`
+	Format24HourTime   bool              `json:"format_24_hour_time"`
+	DefaultEventLength int               `json:"default_event_length,omitempty"`
+	Locale             string            `json:"locale,omitempty"`
+	DateFieldOrder     string            `json:"date_field_order,omitempty"`
+	HideWeekends       bool              `json:"hide_weekends"`
+	ShowDeclinedEvents bool              `json:"show_declined_events"`
+	Settings           map[string]string `json:"settings"`
+}
+
+type GetSettingsOutput struct {
+	Accounts []AccountSettings `json:"accounts"`
+	Errors   []AccountError    `json:"errors,omitempty"`
+}
+
+type GetColorsInput struct {
+	Account string `json:"account,omitempty" jsonschema:"description:Account name (optional - if empty uses the first account)"`
+}
+
+// Color is an entry of the calendar or event color palette, named as in the
+// Google Calendar UI
+type Color struct {
+	ID         string `json:"id"`
+	Name       string `json:"name,omitempty"`
+	Background string `json:"background,omitempty"`
+	Foreground string `json:"foreground,omitempty"`
+}
+
+type GetColorsOutput struct {
+	Account  string  `json:"account"`
+	Calendar []Color `json:"calendar"`
+	Event    []Color `json:"event"`
+}
+
+// toolHandlers holds the dependencies shared by the MCP tool handlers
+type toolHandlers struct {
+	provider CalendarProvider
 }
 
-// NewCalendarServer creates and configures the MCP server with all tools
-func NewCalendarServer() *mcp.Server {
+// NewCalendarServer creates and configures the MCP server with all tools,
+// serving calendar data from the given provider
+func NewCalendarServer(provider CalendarProvider) *mcp.Server {
+	h := &toolHandlers{provider: provider}
+
 	server := mcp.NewServer(&mcp.Implementation{
 		Name:    ServerName,
 		Version: ServerVersion,
@@ -100,35 +623,80 @@ func NewCalendarServer() *mcp.Server {
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "list_accounts",
 		Description: "List all configured Google accounts",
-	}, handleListAccounts)
+	}, h.handleListAccounts)
 
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "list_calendars",
 		Description: "List all calendars accessible by the configured accounts",
-	}, handleListCalendars)
+	}, h.handleListCalendars)
 
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "list_events",
-		Description: "List calendar events within a time range. Can filter by account, calendar, and search query.",
-	}, handleListEvents)
+		Description: "List calendar events within a time range, merged across accounts and calendars in chronological order. Can filter by account, one or more calendars (or all selected calendars), and search query.",
+	}, h.handleListEvents)
 
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "get_event",
 		Description: "Get detailed information about a specific event",
-	}, handleGetEvent)
+	}, h.handleGetEvent)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "list_event_instances",
+		Description: "List the occurrences of a recurring event, along with the recurrence rules of its series",
+	}, h.handleListEventInstances)
 
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "check_availability",
-		Description: "Check free/busy status for specified calendars within a time range",
-	}, handleCheckAvailability)
+		Description: "Check free/busy status for specified calendars within a time range, optionally merged into a single busy timeline across accounts",
+	}, h.handleCheckAvailability)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "find_free_slots",
+		Description: "Find free time slots of a minimum duration within working hours, across accounts and calendars, ranked best first",
+	}, h.handleFindFreeSlots)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "next_event",
+		Description: "Get the event(s) in progress and the next upcoming event across accounts and selected calendars, with the time until it starts. Free events and working locations are ignored.",
+	}, h.handleNextEvent)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "get_agenda",
+		Description: "Show the agenda of a day, work week or week grouped by day, with start and end times, all-day and multi-day events, overlapping events, locations and meeting links. Shows at most 250 events.",
+	}, h.handleGetAgenda)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "find_conflicts",
+		Description: "Find double-booked events across accounts and calendars within a time range, classified as hard conflicts (overlapping) or back-to-back (no buffer between them). Declined and free events are ignored, and at most 5000 events are checked.",
+	}, h.handleFindConflicts)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "calendar_stats",
+		Description: "Measure meeting load over a time range (this week by default): hours in meetings per day and week, one-on-one vs group meetings, focus time left within working hours, longest meeting-free block, top collaborators and breakdown by account and calendar",
+	}, h.handleCalendarStats)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "get_working_location",
+		Description: "Tell where the user works (home, office or another location) and whether they are out of office, day by day, from their working location events (at most 250 of them)",
+	}, h.handleGetWorkingLocation)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "get_settings",
+		Description: "Get the Google Calendar settings of the accounts, such as time zone, week start, 24-hour time format and default event length",
+	}, h.handleGetSettings)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "get_colors",
+		Description: "Get the calendar and event color palettes, giving the name and hex values of each color ID",
+	}, h.handleGetColors)
 
 	return server
 }
 
 // Tool handlers
 
-func handleListAccounts(ctx context.Context, req *mcp.CallToolRequest, input ListAccountsInput) (*mcp.CallToolResult, ListAccountsOutput, error) {
-	accounts, err := ListConfiguredAccounts()
+func (h *toolHandlers) handleListAccounts(ctx context.Context, req *mcp.CallToolRequest, input ListAccountsInput) (*mcp.CallToolResult, ListAccountsOutput, error) {
+	accounts, err := h.provider.Accounts()
 	if err != nil {
 		return nil, ListAccountsOutput{}, fmt.Errorf("failed to list accounts: %w", err)
 	}
@@ -146,61 +714,108 @@ func handleListAccounts(ctx context.Context, req *mcp.CallToolRequest, input Lis
 	}, output, nil
 }
 
-func handleListCalendars(ctx context.Context, req *mcp.CallToolRequest, input ListCalendarsInput) (*mcp.CallToolResult, ListCalendarsOutput, error) {
-	calendars, err := GetCalendars(ctx, input.Account)
+func (h *toolHandlers) handleListCalendars(ctx context.Context, req *mcp.CallToolRequest, input ListCalendarsInput) (*mcp.CallToolResult, ListCalendarsOutput, error) {
+	output, err := GetCalendars(ctx, h.provider, input)
 	if err != nil {
 		return nil, ListCalendarsOutput{}, fmt.Errorf("failed to list calendars: %w", err)
 	}
 
 	// Ensure we return an empty array, not null
-	if calendars == nil {
-		calendars = []Calendar{}
+	if output.Calendars == nil {
+		output.Calendars = []Calendar{}
 	}
 
-	output := ListCalendarsOutput{Calendars: calendars}
-
 	var lines []string
-	for _, cal := range calendars {
+	for _, cal := range output.Calendars {
 		primary := ""
 		if cal.Primary {
 			primary = " (primary)"
 		}
-		lines = append(lines, fmt.Sprintf("- [%s] %s%s", cal.Account, cal.Summary, primary))
+		var details []string
+		for _, detail := range []string{cal.AccessRole, cal.TimeZone} {
+			if detail != "" {
+				details = append(details, detail)
+			}
+		}
+		if cal.Hidden {
+			details = append(details, "hidden")
+		}
+		if cal.Deleted {
+			details = append(details, "deleted")
+		}
+		line := fmt.Sprintf("- [%s] %s%s", cal.Account, cal.Name(), primary)
+		if len(details) > 0 {
+			line += " - " + strings.Join(details, ", ")
+		}
+		if cal.AccessRole == "freeBusyReader" {
+			line += " (free/busy only - events cannot be listed)"
+		}
+		lines = append(lines, line)
+	}
+
+	text := fmt.Sprintf("Found %d calendar(s):\n%s", len(output.Calendars), strings.Join(lines, "\n"))
+	switch {
+	case output.NextCursor != "":
+		text += fmt.Sprintf("\n(more calendars exist - pass cursor %q to see the next ones)", output.NextCursor)
+	case output.Truncated:
+		text += "\n(more calendars exist - raise max_results to see them)"
 	}
+	text += formatAccountErrors(output.Errors)
 
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
-			&mcp.TextContent{Text: fmt.Sprintf("Found %d calendar(s):\n%s", len(calendars), strings.Join(lines, "\n"))},
+			&mcp.TextContent{Text: text},
 		},
 	}, output, nil
 }
 
-func handleListEvents(ctx context.Context, req *mcp.CallToolRequest, input ListEventsInput) (*mcp.CallToolResult, ListEventsOutput, error) {
-	events, err := GetEvents(ctx, input)
+func (h *toolHandlers) handleListEvents(ctx context.Context, req *mcp.CallToolRequest, input ListEventsInput) (*mcp.CallToolResult, ListEventsOutput, error) {
+	output, err := GetEvents(ctx, h.provider, input)
 	if err != nil {
 		return nil, ListEventsOutput{}, fmt.Errorf("failed to list events: %w", err)
 	}
 
 	// Ensure we return an empty array, not null
-	if events == nil {
-		events = []Event{}
+	if output.Events == nil {
+		output.Events = []Event{}
 	}
 
-	output := ListEventsOutput{Events: events}
+	// Name the calendar of each event when several calendars were queried
+	multiCalendar := input.AllCalendars || len(input.CalendarIDs) > 0
 
 	var lines []string
-	for _, ev := range events {
+	for _, ev := range output.Events {
 		timeStr := ev.Start.Format("2006-01-02 15:04")
 		if ev.AllDay {
 			timeStr = ev.Start.Format("2006-01-02") + " (all day)"
 		}
-		lines = append(lines, fmt.Sprintf("- [%s] %s: %s", ev.Account, timeStr, ev.Summary))
+		line := fmt.Sprintf("- [%s] %s: %s", ev.Account, timeStr, ev.Summary)
+		if multiCalendar && ev.CalendarName != "" {
+			line += fmt.Sprintf(" (%s)", ev.CalendarName)
+		}
+		if label := eventTypeLabels[ev.EventType]; label != "" {
+			line += fmt.Sprintf(" [%s]", label)
+		}
+		if len(ev.Recurrence) > 0 {
+			line += fmt.Sprintf(" [repeats: %s]", strings.Join(ev.Recurrence, "; "))
+		}
+		if label := responseLabel(ev.ResponseStatus); label != "" && ev.ResponseStatus != "accepted" {
+			line += fmt.Sprintf(" (you: %s)", label)
+		}
+		lines = append(lines, line)
 	}
 
-	text := fmt.Sprintf("Found %d event(s)", len(events))
+	text := fmt.Sprintf("Found %d event(s) %s (%s)", len(output.Events), formatTimeRange(output.TimeMin, output.TimeMax), output.TimeZone)
 	if len(lines) > 0 {
 		text += ":\n" + strings.Join(lines, "\n")
 	}
+	switch {
+	case output.NextCursor != "":
+		text += fmt.Sprintf("\n(more events match - pass cursor %q to see the next ones)", output.NextCursor)
+	case output.Truncated:
+		text += "\n(more events match - narrow the time range or raise max_results to see them)"
+	}
+	text += formatAccountErrors(output.Errors)
 
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
@@ -209,21 +824,83 @@ func handleListEvents(ctx context.Context, req *mcp.CallToolRequest, input ListE
 	}, output, nil
 }
 
-func handleGetEvent(ctx context.Context, req *mcp.CallToolRequest, input GetEventInput) (*mcp.CallToolResult, GetEventOutput, error) {
-	event, err := GetEvent(ctx, input.Account, input.CalendarID, input.EventID)
+func (h *toolHandlers) handleGetEvent(ctx context.Context, req *mcp.CallToolRequest, input GetEventInput) (*mcp.CallToolResult, GetEventOutput, error) {
+	event, fields, err := GetEvent(ctx, h.provider, input)
 	if err != nil {
 		return nil, GetEventOutput{}, fmt.Errorf("failed to get event: %w", err)
 	}
 
 	output := GetEventOutput{Event: *event}
 
-	text := fmt.Sprintf("Event: %s\nWhen: %s - %s\nWhere: %s\nDescription: %s",
+	text := fmt.Sprintf("Event: %s\nWhen: %s - %s (%s)\nWhere: %s\nDescription: %s",
 		event.Summary,
 		event.Start.Format("2006-01-02 15:04"),
 		event.End.Format("2006-01-02 15:04"),
+		event.Start.Location(),
 		event.Location,
 		event.Description,
 	)
+	if event.TimeZone != "" && event.TimeZone != event.Start.Location().String() {
+		text += "\nScheduled in: " + event.TimeZone
+	}
+	if len(event.Recurrence) > 0 {
+		text += "\nRepeats: " + strings.Join(event.Recurrence, "; ")
+	}
+	if event.RecurringEventID != "" {
+		text += "\nPart of series: " + event.RecurringEventID
+	}
+	if label := eventTypeLabels[event.EventType]; label != "" {
+		text += "\nType: " + label
+	}
+	if c := event.Color; c != nil {
+		text += "\nColor: " + cmp.Or(c.Name, c.ID)
+		if c.Background != "" {
+			text += " (" + c.Background + ")"
+		}
+	}
+	if event.WorkingLocation != nil {
+		text += "\nWorking from: " + formatWorkingLocation(*event.WorkingLocation)
+	}
+	for _, props := range []*StatusProperties{event.OutOfOffice, event.FocusTime} {
+		if props == nil {
+			continue
+		}
+		if props.AutoDeclineMode != "" {
+			text += "\nAuto-decline: " + props.AutoDeclineMode
+		}
+		if props.DeclineMessage != "" {
+			text += "\nDecline message: " + props.DeclineMessage
+		}
+	}
+	if event.ResponseStatus != "" {
+		text += "\nYour response: " + responseLabel(event.ResponseStatus)
+	}
+	if fields["transparency"] {
+		showsAs := "busy"
+		if event.Transparency == "transparent" {
+			showsAs = "free"
+		}
+		text += "\nShows as: " + showsAs
+	}
+	if d := event.Details; d != nil {
+		text += formatEventDetails(*d, len(event.Attendees) > 0)
+	}
+	if conf := event.Conference; conf != nil {
+		text += "\nJoin"
+		if conf.Solution != "" {
+			text += " (" + conf.Solution + ")"
+		}
+		text += ":"
+		for _, ep := range conf.EntryPoints {
+			text += "\n- " + formatEntryPoint(ep)
+		}
+	}
+	if len(event.Attendees) > 0 {
+		text += "\nAttendees:"
+		for _, att := range event.Attendees {
+			text += "\n- " + formatAttendee(att)
+		}
+	}
 
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
@@ -232,38 +909,704 @@ func handleGetEvent(ctx context.Context, req *mcp.CallToolRequest, input GetEven
 	}, output, nil
 }
 
-func handleCheckAvailability(ctx context.Context, req *mcp.CallToolRequest, input CheckAvailabilityInput) (*mcp.CallToolResult, CheckAvailabilityOutput, error) {
-	busyPeriods, err := CheckAvailability(ctx, input)
+func (h *toolHandlers) handleListEventInstances(ctx context.Context, req *mcp.CallToolRequest, input ListEventInstancesInput) (*mcp.CallToolResult, ListEventInstancesOutput, error) {
+	output, err := GetEventInstances(ctx, h.provider, input)
+	if err != nil {
+		return nil, ListEventInstancesOutput{}, fmt.Errorf("failed to list event instances: %w", err)
+	}
+
+	// Ensure we return an empty array, not null
+	if output.Instances == nil {
+		output.Instances = []Event{}
+	}
+
+	var lines []string
+	for _, ev := range output.Instances {
+		timeStr := ev.Start.Format("2006-01-02 15:04")
+		if ev.AllDay {
+			timeStr = ev.Start.Format("2006-01-02") + " (all day)"
+		}
+		line := fmt.Sprintf("- %s: %s", timeStr, ev.Summary)
+		if ev.OriginalStartTime != nil && !ev.OriginalStartTime.Equal(ev.Start) {
+			line += fmt.Sprintf(" (moved from %s)", ev.OriginalStartTime.Format("2006-01-02 15:04"))
+		}
+		if ev.Status == "cancelled" {
+			line += " (cancelled)"
+		}
+		lines = append(lines, line)
+	}
+
+	text := fmt.Sprintf("Series: %s", output.Series.Summary)
+	if len(output.Series.Recurrence) > 0 {
+		text += "\nRepeats: " + strings.Join(output.Series.Recurrence, "; ")
+	}
+	text += fmt.Sprintf("\nFound %d instance(s) %s (%s)", len(output.Instances), formatTimeRange(output.TimeMin, output.TimeMax), output.TimeZone)
+	if len(lines) > 0 {
+		text += ":\n" + strings.Join(lines, "\n")
+	}
+	switch {
+	case output.NextCursor != "":
+		text += fmt.Sprintf("\n(more instances follow - pass cursor %q to see the next ones)", output.NextCursor)
+	case output.Truncated:
+		text += "\n(more instances follow - narrow the time range or raise max_results to see them)"
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: text},
+		},
+	}, output, nil
+}
+
+func (h *toolHandlers) handleCheckAvailability(ctx context.Context, req *mcp.CallToolRequest, input CheckAvailabilityInput) (*mcp.CallToolResult, CheckAvailabilityOutput, error) {
+	output, err := CheckAvailability(ctx, h.provider, input)
 	if err != nil {
 		return nil, CheckAvailabilityOutput{}, fmt.Errorf("failed to check availability: %w", err)
 	}
 
 	// Ensure we return an empty array, not null
-	if busyPeriods == nil {
-		busyPeriods = []BusyPeriod{}
+	if output.BusyPeriods == nil {
+		output.BusyPeriods = []BusyPeriod{}
+	}
+
+	var lines []string
+	if input.Merge {
+		for _, block := range output.BusyBlocks {
+			var sources []string
+			for _, src := range block.Sources {
+				sources = append(sources, src.Account+": "+cmp.Or(src.CalendarID, "primary"))
+			}
+			lines = append(lines, fmt.Sprintf("- %s - %s (%s)",
+				block.Start.Format("2006-01-02 15:04"),
+				block.End.Format("2006-01-02 15:04"),
+				strings.Join(sources, ", "),
+			))
+		}
+	} else {
+		for _, bp := range output.BusyPeriods {
+			lines = append(lines, fmt.Sprintf("- [%s] %s - %s",
+				bp.Account,
+				bp.Start.Format("2006-01-02 15:04"),
+				bp.End.Format("2006-01-02 15:04"),
+			))
+		}
+	}
+
+	period := fmt.Sprintf("%s (%s)", formatTimeRange(output.TimeMin, output.TimeMax), output.TimeZone)
+	text := fmt.Sprintf("Found %d busy period(s) %s", len(output.BusyPeriods), period)
+	if input.Merge {
+		text = fmt.Sprintf("Found %d busy block(s) from %d busy period(s) %s", len(output.BusyBlocks), len(output.BusyPeriods), period)
+	}
+	// Never claim the user is free when part of the calendars could not be checked
+	switch {
+	case len(lines) > 0:
+		text += ":\n" + strings.Join(lines, "\n")
+		if len(output.Errors) > 0 {
+			text += "\n\nThis list is incomplete: some calendars could not be checked."
+		}
+	case len(output.Errors) == 0:
+		text += " - you're free during this time range!"
+	default:
+		text += " in the calendars that could be checked - availability is unknown for the others."
 	}
+	text += formatAccountErrors(output.Errors)
 
-	output := CheckAvailabilityOutput{BusyPeriods: busyPeriods}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: text},
+		},
+	}, output, nil
+}
+
+func (h *toolHandlers) handleFindFreeSlots(ctx context.Context, req *mcp.CallToolRequest, input FindFreeSlotsInput) (*mcp.CallToolResult, FindFreeSlotsOutput, error) {
+	output, err := FindFreeSlots(ctx, h.provider, input)
+	if err != nil {
+		return nil, FindFreeSlotsOutput{}, fmt.Errorf("failed to find free slots: %w", err)
+	}
+
+	// Ensure we return an empty array, not null
+	if output.Slots == nil {
+		output.Slots = []FreeSlot{}
+	}
 
 	var lines []string
-	for _, bp := range busyPeriods {
-		lines = append(lines, fmt.Sprintf("- [%s] %s - %s",
-			bp.Account,
-			bp.Start.Format("2006-01-02 15:04"),
-			bp.End.Format("2006-01-02 15:04"),
+	for _, slot := range output.Slots {
+		lines = append(lines, fmt.Sprintf("%d. %s - %s (%d min)",
+			slot.Rank,
+			slot.Start.Format("Mon 2006-01-02 15:04"),
+			slot.End.Format("15:04"),
+			slot.DurationMinutes,
 		))
 	}
 
-	text := fmt.Sprintf("Found %d busy period(s)", len(busyPeriods))
+	text := fmt.Sprintf("Found %d free slot(s) %s (%s)", len(output.Slots), formatTimeRange(output.TimeMin, output.TimeMax), output.TimeZone)
 	if len(lines) > 0 {
 		text += ":\n" + strings.Join(lines, "\n")
+	}
+	if len(output.Errors) > 0 {
+		text += "\n\nThese slots may not be free: some calendars could not be checked."
+	}
+	text += formatAccountErrors(output.Errors)
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: text},
+		},
+	}, output, nil
+}
+
+func (h *toolHandlers) handleNextEvent(ctx context.Context, req *mcp.CallToolRequest, input NextEventInput) (*mcp.CallToolResult, NextEventOutput, error) {
+	output, err := NextEvent(ctx, h.provider, input)
+	if err != nil {
+		return nil, NextEventOutput{}, fmt.Errorf("failed to get next event: %w", err)
+	}
+
+	// Ensure we return an empty array, not null
+	if output.Current == nil {
+		output.Current = []Event{}
+	}
+
+	var sections []string
+	if len(output.Current) > 0 {
+		lines := []string{"In progress:"}
+		for _, ev := range output.Current {
+			lines = append(lines, fmt.Sprintf("- [%s] %s, until %s (ends in %s)",
+				ev.Account, ev.Summary, ev.End.Format("15:04"), formatDuration(ev.End.Sub(output.Now))))
+		}
+		sections = append(sections, strings.Join(lines, "\n"))
+	}
+
+	if ev := output.Next; ev != nil {
+		when := dayLabel(ev.Start, output.Now)
+		if !ev.AllDay {
+			when += fmt.Sprintf(" %s-%s", ev.Start.Format("15:04"), ev.End.Format("15:04"))
+		}
+		next := fmt.Sprintf("Next: [%s] %s, %s (in %s)", ev.Account, ev.Summary, when, output.StartsIn)
+		if ev.MeetingURL != "" {
+			next += "\nJoin: " + ev.MeetingURL
+		}
+		if ev.Location != "" {
+			next += "\nLocation: " + ev.Location
+		}
+		sections = append(sections, next)
 	} else {
-		text += " - you're free during this time range!"
+		sections = append(sections, fmt.Sprintf("No upcoming event in the next %d day(s).", output.WithinDays))
 	}
 
+	text := fmt.Sprintf("Now: %s (%s)\n\n%s", output.Now.Format("Mon 2006-01-02 15:04"), output.TimeZone, strings.Join(sections, "\n\n"))
+	text += formatAccountErrors(output.Errors)
+
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
 			&mcp.TextContent{Text: text},
 		},
 	}, output, nil
 }
+
+func (h *toolHandlers) handleGetAgenda(ctx context.Context, req *mcp.CallToolRequest, input AgendaInput) (*mcp.CallToolResult, AgendaOutput, error) {
+	output, err := GetAgenda(ctx, h.provider, input)
+	if err != nil {
+		return nil, AgendaOutput{}, fmt.Errorf("failed to get agenda: %w", err)
+	}
+
+	last := output.TimeMax.AddDate(0, 0, -1)
+	text := fmt.Sprintf("Agenda for %s", output.TimeMin.Format("Mon 2006-01-02"))
+	if last.After(output.TimeMin) {
+		text += " - " + last.Format("Mon 2006-01-02")
+	}
+	text += fmt.Sprintf(" (%s)", output.TimeZone)
+
+	for _, day := range output.Days {
+		lines := []string{fmt.Sprintf("%s %s", day.Weekday, day.Date)}
+		if len(day.Items) == 0 {
+			lines = append(lines, "- No events")
+		}
+		for _, item := range day.Items {
+			ev := item.Event
+			line := fmt.Sprintf("- %s [%s] %s", agendaTime(item), ev.Account, ev.Summary)
+			if item.Days > 1 {
+				line += fmt.Sprintf(" (day %d of %d)", item.Day, item.Days)
+			}
+			if label := responseLabel(ev.ResponseStatus); label != "" && ev.ResponseStatus != "accepted" {
+				line += fmt.Sprintf(" (you: %s)", label)
+			}
+			lines = append(lines, line)
+			if ev.Location != "" {
+				lines = append(lines, "  Location: "+ev.Location)
+			}
+			if ev.MeetingURL != "" {
+				lines = append(lines, "  Join: "+ev.MeetingURL)
+			}
+			if len(item.Overlaps) > 0 {
+				var names []string
+				for _, ref := range item.Overlaps {
+					names = append(names, cmp.Or(ref.Summary, ref.EventID))
+				}
+				lines = append(lines, "  Overlaps with: "+strings.Join(names, ", "))
+			}
+		}
+		text += "\n\n" + strings.Join(lines, "\n")
+	}
+
+	if output.Truncated {
+		text += "\n\n(more events match - narrow the agenda to fewer calendars to see them)"
+	}
+	text += formatAccountErrors(output.Errors)
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: text},
+		},
+	}, output, nil
+}
+
+func (h *toolHandlers) handleFindConflicts(ctx context.Context, req *mcp.CallToolRequest, input FindConflictsInput) (*mcp.CallToolResult, FindConflictsOutput, error) {
+	output, err := FindConflicts(ctx, h.provider, input)
+	if err != nil {
+		return nil, FindConflictsOutput{}, fmt.Errorf("failed to find conflicts: %w", err)
+	}
+
+	// Ensure we return an empty array, not null
+	if output.Conflicts == nil {
+		output.Conflicts = []Conflict{}
+	}
+
+	var overlaps, backToBack []string
+	for _, c := range output.Conflicts {
+		a, b := c.Events[0], c.Events[1]
+		if c.Kind == "overlap" {
+			overlaps = append(overlaps, fmt.Sprintf("- %s %s-%s (%d min): [%s] %s %s-%s and [%s] %s %s-%s",
+				c.Start.Format("Mon 2006-01-02"), c.Start.Format("15:04"), c.End.Format("15:04"), c.Minutes,
+				a.Account, a.Summary, a.Start.Format("15:04"), a.End.Format("15:04"),
+				b.Account, b.Summary, b.Start.Format("15:04"), b.End.Format("15:04")))
+			continue
+		}
+		gap := "no break"
+		if c.Minutes > 0 {
+			gap = fmt.Sprintf("%d min break", c.Minutes)
+		}
+		backToBack = append(backToBack, fmt.Sprintf("- %s %s: [%s] %s ends, [%s] %s starts at %s (%s)",
+			c.Start.Format("Mon 2006-01-02"), c.Start.Format("15:04"), a.Account, a.Summary,
+			b.Account, b.Summary, b.Start.Format("15:04"), gap))
+	}
+
+	text := fmt.Sprintf("Found %d hard conflict(s) and %d back-to-back pair(s) among %d event(s) %s (%s)",
+		len(overlaps), len(backToBack), output.Checked, formatTimeRange(output.TimeMin, output.TimeMax), output.TimeZone)
+	if len(overlaps) > 0 {
+		text += "\n\nHard conflicts:\n" + strings.Join(overlaps, "\n")
+	}
+	if len(backToBack) > 0 {
+		text += "\n\nBack-to-back:\n" + strings.Join(backToBack, "\n")
+	}
+	if output.Truncated {
+		text += fmt.Sprintf("\n\n(more events match - only the first %d events were checked; narrow the time range to check them all)", maxConflictEvents)
+	}
+	if len(output.Errors) > 0 {
+		text += "\n\nThis list may be incomplete: some calendars could not be checked."
+	}
+	text += formatAccountErrors(output.Errors)
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: text},
+		},
+	}, output, nil
+}
+
+func (h *toolHandlers) handleCalendarStats(ctx context.Context, req *mcp.CallToolRequest, input CalendarStatsInput) (*mcp.CallToolResult, CalendarStatsOutput, error) {
+	output, err := CalendarStats(ctx, h.provider, input)
+	if err != nil {
+		return nil, CalendarStatsOutput{}, fmt.Errorf("failed to compute calendar stats: %w", err)
+	}
+
+	// Ensure we return empty arrays, not null
+	if output.Days == nil {
+		output.Days = []DayStats{}
+	}
+	if output.Weeks == nil {
+		output.Weeks = []WeekStats{}
+	}
+	if output.Collaborators == nil {
+		output.Collaborators = []CollaboratorStats{}
+	}
+	if output.Calendars == nil {
+		output.Calendars = []MeetingLoad{}
+	}
+	if output.Accounts == nil {
+		output.Accounts = []MeetingLoad{}
+	}
+
+	lines := []string{
+		fmt.Sprintf("Calendar stats %s (%s), working hours %s", formatTimeRange(output.TimeMin, output.TimeMax), output.TimeZone, output.WorkingHours),
+		"",
+		fmt.Sprintf("Meetings: %d (%d one-on-one, %d group), %s in meetings", output.Meetings, output.OneOnOneMeetings, output.GroupMeetings, formatMinutes(output.MeetingMinutes)),
+		fmt.Sprintf("Other busy events: %d", output.SoloEvents),
+		fmt.Sprintf("Focus time left: %s", formatMinutes(output.FocusMinutes)),
+	}
+	if b := output.LongestFreeBlock; b != nil {
+		lines = append(lines, fmt.Sprintf("Longest meeting-free block: %s %s-%s (%s)",
+			b.Start.Format("Mon 2006-01-02"), b.Start.Format("15:04"), b.End.Format("15:04"), formatMinutes(b.DurationMinutes)))
+	}
+
+	lines = append(lines, "", "Per day:")
+	for _, day := range output.Days {
+		lines = append(lines, fmt.Sprintf("- %s %s: %d meeting(s), %s in meetings, %s focus",
+			day.Weekday[:3], day.Date, day.Meetings, formatMinutes(day.MeetingMinutes), formatMinutes(day.FocusMinutes)))
+	}
+	if len(output.Weeks) > 1 {
+		lines = append(lines, "", "Per week:")
+		for _, week := range output.Weeks {
+			lines = append(lines, fmt.Sprintf("- %s: %d meeting(s), %s in meetings, %s focus",
+				week.Week, week.Meetings, formatMinutes(week.MeetingMinutes), formatMinutes(week.FocusMinutes)))
+		}
+	}
+	if len(output.Collaborators) > 0 {
+		lines = append(lines, "", "Top collaborators:")
+		for _, c := range output.Collaborators {
+			name := c.Email
+			if c.DisplayName != "" {
+				name = fmt.Sprintf("%s <%s>", c.DisplayName, c.Email)
+			}
+			lines = append(lines, fmt.Sprintf("- %s: %d meeting(s), %s", name, c.Meetings, formatMinutes(c.Minutes)))
+		}
+	}
+	if len(output.Calendars) > 0 {
+		lines = append(lines, "", "By calendar:")
+		for _, load := range output.Calendars {
+			lines = append(lines, fmt.Sprintf("- [%s] %s: %d meeting(s), %s",
+				load.Account, cmp.Or(load.CalendarName, load.CalendarID), load.Meetings, formatMinutes(load.Minutes)))
+		}
+	}
+
+	text := strings.Join(lines, "\n")
+	if output.Truncated {
+		text += fmt.Sprintf("\n\n(more events match - these stats only cover the first %d events; narrow the time range)", maxStatsEvents)
+	}
+	if len(output.Errors) > 0 {
+		text += "\n\nThese stats are incomplete: some calendars could not be read."
+	}
+	text += formatAccountErrors(output.Errors)
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: text},
+		},
+	}, output, nil
+}
+
+func (h *toolHandlers) handleGetWorkingLocation(ctx context.Context, req *mcp.CallToolRequest, input WorkingLocationInput) (*mcp.CallToolResult, WorkingLocationOutput, error) {
+	output, err := GetWorkingLocation(ctx, h.provider, input)
+	if err != nil {
+		return nil, WorkingLocationOutput{}, fmt.Errorf("failed to get working location: %w", err)
+	}
+
+	// Ensure we return an empty array, not null
+	if output.Days == nil {
+		output.Days = []WorkingDay{}
+	}
+
+	var lines []string
+	for _, day := range output.Days {
+		var places []string
+		for _, entry := range day.Locations {
+			place := fmt.Sprintf("%s [%s]", formatWorkingLocation(entry.Location), entry.Account)
+			if !entry.AllDay {
+				place += fmt.Sprintf(" %s-%s", entry.Start.Format("15:04"), entry.End.Format("15:04"))
+			}
+			places = append(places, place)
+		}
+		if day.OutOfOffice {
+			places = append([]string{"out of office"}, places...)
+		}
+		if len(places) == 0 {
+			places = []string{"no working location set"}
+		}
+		lines = append(lines, fmt.Sprintf("- %s %s: %s", day.Weekday[:3], day.Date, strings.Join(places, "; ")))
+	}
+
+	text := fmt.Sprintf("Working location %s (%s):\n%s", formatTimeRange(output.TimeMin, output.TimeMax), output.TimeZone, strings.Join(lines, "\n"))
+	text += formatAccountErrors(output.Errors)
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: text},
+		},
+	}, output, nil
+}
+
+func (h *toolHandlers) handleGetSettings(ctx context.Context, req *mcp.CallToolRequest, input GetSettingsInput) (*mcp.CallToolResult, GetSettingsOutput, error) {
+	output, err := GetSettings(ctx, h.provider, input)
+	if err != nil {
+		return nil, GetSettingsOutput{}, fmt.Errorf("failed to get settings: %w", err)
+	}
+
+	// Ensure we return an empty array, not null
+	if output.Accounts == nil {
+		output.Accounts = []AccountSettings{}
+	}
+
+	var sections []string
+	for _, acc := range output.Accounts {
+		clock := "12-hour"
+		if acc.Format24HourTime {
+			clock = "24-hour"
+		}
+		lines := []string{fmt.Sprintf("[%s]", acc.Account)}
+		for _, setting := range [][2]string{
+			{"Time zone", acc.TimeZone},
+			{"Week starts on", acc.WeekStart},
+			{"Time format", clock},
+			{"Locale", acc.Locale},
+			{"Date order", acc.DateFieldOrder},
+		} {
+			if setting[1] != "" {
+				lines = append(lines, fmt.Sprintf("- %s: %s", setting[0], setting[1]))
+			}
+		}
+		if acc.DefaultEventLength > 0 {
+			lines = append(lines, fmt.Sprintf("- Default event length: %d minutes", acc.DefaultEventLength))
+		}
+		if acc.HideWeekends {
+			lines = append(lines, "- Weekends hidden")
+		}
+		if acc.ShowDeclinedEvents {
+			lines = append(lines, "- Declined events shown")
+		}
+		sections = append(sections, strings.Join(lines, "\n"))
+	}
+
+	text := fmt.Sprintf("Settings of %d account(s):\n%s", len(output.Accounts), strings.Join(sections, "\n\n"))
+	text += formatAccountErrors(output.Errors)
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: text},
+		},
+	}, output, nil
+}
+
+func (h *toolHandlers) handleGetColors(ctx context.Context, req *mcp.CallToolRequest, input GetColorsInput) (*mcp.CallToolResult, GetColorsOutput, error) {
+	output, err := GetColors(ctx, h.provider, input)
+	if err != nil {
+		return nil, GetColorsOutput{}, fmt.Errorf("failed to get colors: %w", err)
+	}
+
+	var lines []string
+	for _, palette := range []struct {
+		title  string
+		colors []Color
+	}{{"Event colors", output.Event}, {"Calendar colors", output.Calendar}} {
+		lines = append(lines, palette.title+":")
+		for _, c := range palette.colors {
+			lines = append(lines, "- "+formatColor(c))
+		}
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: strings.Join(lines, "\n")},
+		},
+	}, output, nil
+}
+
+// Helper functions
+
+// formatEventDetails renders the details of an event as lines of get_event,
+// with the guest permissions only for events that have guests
+func formatEventDetails(d EventDetails, hasGuests bool) string {
+	var text string
+	if d.Visibility != "" && d.Visibility != "default" {
+		text += "\nVisibility: " + d.Visibility
+	}
+	if r := d.Reminders; r != nil {
+		text += "\nReminders: " + formatReminders(*r)
+	}
+	if len(d.Attachments) > 0 {
+		text += "\nAttachments:"
+		for _, a := range d.Attachments {
+			line := "\n- " + cmp.Or(a.Title, a.FileURL)
+			if a.MimeType != "" {
+				line += " (" + a.MimeType + ")"
+			}
+			if a.FileURL != "" && a.Title != "" {
+				line += " " + a.FileURL
+			}
+			text += line
+		}
+	}
+	if hasGuests {
+		var perms []string
+		if d.GuestsCanModify {
+			perms = append(perms, "modify the event")
+		}
+		if d.GuestsCanInviteOthers {
+			perms = append(perms, "invite others")
+		}
+		if d.GuestsCanSeeOtherGuests {
+			perms = append(perms, "see the guest list")
+		}
+		if len(perms) > 0 {
+			text += "\nGuests can: " + strings.Join(perms, ", ")
+		} else {
+			text += "\nGuests can: only attend"
+		}
+	}
+	if s := d.Source; s != nil {
+		text += "\nSource: " + strings.TrimSpace(s.Title+" "+s.URL)
+	}
+	if !d.Created.IsZero() {
+		text += "\nCreated: " + d.Created.Format("2006-01-02 15:04")
+		if d.Creator != "" {
+			text += " by " + d.Creator
+		}
+	}
+	if !d.Updated.IsZero() {
+		text += "\nUpdated: " + d.Updated.Format("2006-01-02 15:04")
+	}
+	return text
+}
+
+// formatReminders renders reminders as "default" or e.g. "popup 10m before, email 1d before"
+func formatReminders(r Reminders) string {
+	if r.UseDefault {
+		return "calendar default"
+	}
+	if len(r.Overrides) == 0 {
+		return "none"
+	}
+	var parts []string
+	for _, o := range r.Overrides {
+		when := "at start"
+		if o.Minutes > 0 {
+			when = formatDuration(time.Duration(o.Minutes)*time.Minute) + " before"
+		}
+		parts = append(parts, o.Method+" "+when)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatColor renders a color as "11 Tomato (#dc2127)"
+func formatColor(c Color) string {
+	text := c.ID
+	if c.Name != "" {
+		text += " " + c.Name
+	}
+	if c.Background != "" {
+		text += " (" + c.Background + ")"
+	}
+	return text
+}
+
+// responseLabel renders an attendee response status
+func responseLabel(status string) string {
+	switch status {
+	case "needsAction":
+		return "not answered"
+	case "tentative":
+		return "maybe"
+	default:
+		return status
+	}
+}
+
+// formatAttendee renders an attendee as "Name <email> (response, flags)"
+func formatAttendee(att Attendee) string {
+	name := att.Email
+	if att.DisplayName != "" {
+		name = fmt.Sprintf("%s <%s>", att.DisplayName, att.Email)
+	}
+
+	details := []string{responseLabel(att.ResponseStatus)}
+	if att.Organizer {
+		details = append(details, "organizer")
+	}
+	if att.Self {
+		details = append(details, "you")
+	}
+	if att.Optional {
+		details = append(details, "optional")
+	}
+	if att.Resource {
+		details = append(details, "resource")
+	}
+	if att.AdditionalGuests > 0 {
+		details = append(details, fmt.Sprintf("+%d guest(s)", att.AdditionalGuests))
+	}
+	return fmt.Sprintf("%s (%s)", name, strings.Join(details, ", "))
+}
+
+// formatEntryPoint renders a conference entry point with its access codes
+func formatEntryPoint(ep EntryPoint) string {
+	line := fmt.Sprintf("%s: %s", ep.Type, ep.URI)
+	if ep.Label != "" && ep.Label != ep.URI {
+		line += fmt.Sprintf(" (%s)", ep.Label)
+	}
+	if ep.PIN != "" {
+		line += " PIN " + ep.PIN
+	}
+	if ep.Passcode != "" {
+		line += " passcode " + ep.Passcode
+	}
+	return line
+}
+
+// formatTimeRange renders the resolved time range of a tool call; a zero
+// timeMax leaves the range open
+func formatTimeRange(timeMin, timeMax time.Time) string {
+	if timeMax.IsZero() {
+		return "from " + timeMin.Format("2006-01-02 15:04")
+	}
+	return fmt.Sprintf("between %s and %s", timeMin.Format("2006-01-02 15:04"), timeMax.Format("2006-01-02 15:04"))
+}
+
+// formatAccountErrors renders per-account failures as a trailing text block
+func formatAccountErrors(errs []AccountError) string {
+	if len(errs) == 0 {
+		return ""
+	}
+
+	var accounts, calendars int
+	for _, e := range errs {
+		if e.CalendarID != "" {
+			calendars++
+		} else {
+			accounts++
+		}
+	}
+
+	var counts []string
+	if accounts > 0 {
+		counts = append(counts, fmt.Sprintf("%d account(s)", accounts))
+	}
+	if calendars > 0 {
+		counts = append(counts, fmt.Sprintf("%d calendar(s)", calendars))
+	}
+
+	lines := []string{fmt.Sprintf("\n\nCould not query %s:", strings.Join(counts, " and "))}
+	for _, e := range errs {
+		if e.CalendarID != "" {
+			lines = append(lines, fmt.Sprintf("- [%s] %s: %s", e.Account, e.CalendarID, e.Error))
+		} else {
+			lines = append(lines, fmt.Sprintf("- [%s] %s", e.Account, e.Error))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// agendaTime renders when an agenda item happens on its day: its time range,
+// or "all day", "from" or "until" for events spanning several days
+func agendaTime(item AgendaItem) string {
+	ev := item.Event
+	switch {
+	case ev.AllDay:
+		return "all day"
+	case item.Days <= 1:
+		return ev.Start.Format("15:04") + "-" + ev.End.Format("15:04")
+	case item.Day == 1:
+		return "from " + ev.Start.Format("15:04")
+	case item.Day == item.Days:
+		return "until " + ev.End.Format("15:04")
+	default:
+		return "all day"
+	}
+}
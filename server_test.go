@@ -1,11 +1,149 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
+// fakeProvider is an in-memory CalendarProvider keyed by account name
+type fakeProvider struct {
+	accounts  []string
+	calendars map[string][]Calendar
+	events    map[string][]Event
+	busy      map[string][]BusyPeriod
+	truncated map[string]bool
+	aliases   map[string]map[string]string
+	timeZone  string
+	settings  map[string]map[string]string
+	palette   *ColorPalette
+	err       error
+
+	// accountErrs makes every call on the given accounts fail
+	accountErrs map[string]error
+
+	mu      sync.Mutex
+	queries []EventQuery
+}
+
+func (f *fakeProvider) Accounts() ([]string, error) {
+	return f.accounts, f.err
+}
+
+func (f *fakeProvider) AccountConfig(account string) (AccountConfig, error) {
+	return AccountConfig{Name: account, CalendarAliases: f.aliases[account], TimeZone: f.timeZone}, f.err
+}
+
+func (f *fakeProvider) fail(account string) error {
+	if err := f.accountErrs[account]; err != nil {
+		return err
+	}
+	return f.err
+}
+
+func (f *fakeProvider) ListCalendars(ctx context.Context, account string, query CalendarQuery) ([]Calendar, string, error) {
+	if err := f.fail(account); err != nil {
+		return nil, "", err
+	}
+	return f.calendars[account], "", nil
+}
+
+func (f *fakeProvider) ListEvents(ctx context.Context, account string, query EventQuery) ([]Event, bool, error) {
+	f.mu.Lock()
+	f.queries = append(f.queries, query)
+	f.mu.Unlock()
+	if err := f.fail(account); err != nil {
+		return nil, false, err
+	}
+	return f.events[account], f.truncated[account], nil
+}
+
+func (f *fakeProvider) ListInstances(ctx context.Context, account string, query InstancesQuery) ([]Event, string, error) {
+	if err := f.fail(account); err != nil {
+		return nil, "", err
+	}
+	var instances []Event
+	for _, ev := range f.events[account] {
+		if ev.RecurringEventID == query.EventID {
+			instances = append(instances, ev)
+		}
+	}
+	return instances, "", nil
+}
+
+func (f *fakeProvider) GetEvent(ctx context.Context, account, calendarID, eventID string, fields []string) (*Event, error) {
+	if err := f.fail(account); err != nil {
+		return nil, err
+	}
+	for _, ev := range f.events[account] {
+		if ev.ID == eventID {
+			return &ev, nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+func (f *fakeProvider) QueryFreeBusy(ctx context.Context, account string, calendarIDs []string, timeMin, timeMax time.Time) ([]BusyPeriod, []AccountError, error) {
+	if err := f.fail(account); err != nil {
+		return nil, nil, err
+	}
+	return f.busy[account], nil, nil
+}
+
+func (f *fakeProvider) Settings(ctx context.Context, account string) (map[string]string, error) {
+	if err := f.fail(account); err != nil {
+		return nil, err
+	}
+	return f.settings[account], nil
+}
+
+func (f *fakeProvider) Colors(ctx context.Context, account string) (*ColorPalette, error) {
+	if err := f.fail(account); err != nil {
+		return nil, err
+	}
+	if f.palette == nil {
+		return nil, errors.New("no palette")
+	}
+	return f.palette, nil
+}
+
+func newTestHandlers() (*toolHandlers, *fakeProvider) {
+	provider := &fakeProvider{
+		accounts: []string{"personal", "work"},
+		calendars: map[string][]Calendar{
+			"personal": {{ID: "primary", Summary: "Me", Primary: true, Account: "personal"}},
+			"work":     {{ID: "team@example.com", Summary: "Team", Account: "work"}},
+		},
+		events: map[string][]Event{
+			"personal": {{ID: "p1", Summary: "Dentist", Start: time.Date(2026, 2, 2, 9, 0, 0, 0, time.UTC), Account: "personal", CalendarID: "primary"}},
+			"work":     {{ID: "w1", Summary: "Standup", Start: time.Date(2026, 2, 2, 10, 0, 0, 0, time.UTC), Account: "work", CalendarID: "primary"}},
+		},
+		busy: map[string][]BusyPeriod{
+			"work": {{Start: time.Date(2026, 2, 2, 10, 0, 0, 0, time.UTC), End: time.Date(2026, 2, 2, 11, 0, 0, 0, time.UTC), Account: "work"}},
+		},
+		timeZone: "UTC",
+	}
+	return &toolHandlers{provider: provider}, provider
+}
+
+func resultText(t *testing.T, res *mcp.CallToolResult) string {
+	t.Helper()
+	if res == nil || len(res.Content) == 0 {
+		t.Fatal("expected text content in result")
+	}
+	text, ok := res.Content[0].(*mcp.TextContent)
+	if !ok {
+		t.Fatalf("expected *mcp.TextContent, got %T", res.Content[0])
+	}
+	return text.Text
+}
+
 // TestNewCalendarServer verifies that the server initializes without panic.
 // This catches jsonschema tag format errors (e.g., description= vs description:)
 // which would cause a panic during tool registration.
@@ -16,7 +154,7 @@ func TestNewCalendarServer(t *testing.T) {
 		}
 	}()
 
-	server := NewCalendarServer()
+	server := NewCalendarServer(&fakeProvider{})
 	if server == nil {
 		t.Error("NewCalendarServer() returned nil")
 	}
@@ -213,6 +351,192 @@ func TestNilSliceInitialization(t *testing.T) {
 	})
 }
 
+// TestHandleListCalendars verifies calendars from all accounts are returned
+func TestHandleListCalendars(t *testing.T) {
+	h, _ := newTestHandlers()
+
+	res, out, err := h.handleListCalendars(context.Background(), nil, ListCalendarsInput{})
+	if err != nil {
+		t.Fatalf("handleListCalendars() error: %v", err)
+	}
+	if len(out.Calendars) != 2 {
+		t.Fatalf("Expected 2 calendars, got %d", len(out.Calendars))
+	}
+	if text := resultText(t, res); !strings.Contains(text, "[personal] Me (primary)") {
+		t.Errorf("Expected primary calendar in text, got %q", text)
+	}
+}
+
+// TestHandleListEvents verifies defaults are applied and accounts are queried
+func TestHandleListEvents(t *testing.T) {
+	h, provider := newTestHandlers()
+
+	res, out, err := h.handleListEvents(context.Background(), nil, ListEventsInput{
+		TimeMin: "2026-02-02T00:00:00Z",
+	})
+	if err != nil {
+		t.Fatalf("handleListEvents() error: %v", err)
+	}
+	if len(out.Events) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(out.Events))
+	}
+	if out.Events[0].ID != "p1" || out.Events[1].ID != "w1" {
+		t.Errorf("Expected events in chronological order, got %+v", out.Events)
+	}
+	if text := resultText(t, res); !strings.Contains(text, "[work] 2026-02-02 10:00: Standup") {
+		t.Errorf("Expected standup in text, got %q", text)
+	}
+
+	if len(provider.queries) != 2 {
+		t.Fatalf("Expected 2 provider queries, got %d", len(provider.queries))
+	}
+	// One event more than the 50 returned is read, to order the last one
+	q := provider.queries[0]
+	if q.CalendarID != "primary" || q.MaxResults != 51 {
+		t.Errorf("Expected primary calendar and 51 results, got %+v", q)
+	}
+	if want := time.Date(2026, 2, 9, 0, 0, 0, 0, time.UTC); !q.TimeMax.Equal(want) {
+		t.Errorf("Expected time_max %v, got %v", want, q.TimeMax)
+	}
+}
+
+// TestHandleListEventsTruncated verifies truncation is reported in output and text
+func TestHandleListEventsTruncated(t *testing.T) {
+	h, provider := newTestHandlers()
+	provider.truncated = map[string]bool{"work": true}
+
+	res, out, err := h.handleListEvents(context.Background(), nil, ListEventsInput{})
+	if err != nil {
+		t.Fatalf("handleListEvents() error: %v", err)
+	}
+	if !out.Truncated {
+		t.Error("Expected truncated output")
+	}
+	if text := resultText(t, res); !strings.Contains(text, "more events match") {
+		t.Errorf("Expected truncation note in text, got %q", text)
+	}
+}
+
+// TestHandleListEventsInvalidTime verifies malformed time ranges are rejected
+func TestHandleListEventsInvalidTime(t *testing.T) {
+	h, _ := newTestHandlers()
+
+	_, _, err := h.handleListEvents(context.Background(), nil, ListEventsInput{TimeMin: "tomorrow-ish"})
+	if err == nil || !strings.Contains(err.Error(), "invalid time_min format") {
+		t.Errorf("Expected invalid time_min error, got %v", err)
+	}
+}
+
+// TestHandleListEventsEmpty verifies no events still yields an empty array
+func TestHandleListEventsEmpty(t *testing.T) {
+	h := &toolHandlers{provider: &fakeProvider{accounts: []string{"personal"}}}
+
+	_, out, err := h.handleListEvents(context.Background(), nil, ListEventsInput{})
+	if err != nil {
+		t.Fatalf("handleListEvents() error: %v", err)
+	}
+	if out.Events == nil {
+		t.Error("Expected empty events slice, got nil")
+	}
+}
+
+// TestHandleGetEvent verifies a single event is looked up on its account
+func TestHandleGetEvent(t *testing.T) {
+	h, _ := newTestHandlers()
+
+	res, out, err := h.handleGetEvent(context.Background(), nil, GetEventInput{
+		Account:    "work",
+		CalendarID: "primary",
+		EventID:    "w1",
+	})
+	if err != nil {
+		t.Fatalf("handleGetEvent() error: %v", err)
+	}
+	if out.Event.Summary != "Standup" {
+		t.Errorf("Expected Standup, got %q", out.Event.Summary)
+	}
+	if text := resultText(t, res); !strings.HasPrefix(text, "Event: Standup") {
+		t.Errorf("Unexpected text %q", text)
+	}
+}
+
+// TestHandleCheckAvailability verifies busy periods are aggregated across accounts
+func TestHandleCheckAvailability(t *testing.T) {
+	h, _ := newTestHandlers()
+
+	res, out, err := h.handleCheckAvailability(context.Background(), nil, CheckAvailabilityInput{
+		TimeMin: "2026-02-02T00:00:00Z",
+		TimeMax: "2026-02-03T00:00:00Z",
+	})
+	if err != nil {
+		t.Fatalf("handleCheckAvailability() error: %v", err)
+	}
+	if len(out.BusyPeriods) != 1 {
+		t.Fatalf("Expected 1 busy period, got %d", len(out.BusyPeriods))
+	}
+	if text := resultText(t, res); !strings.Contains(text, "[work] 2026-02-02 10:00 - 2026-02-02 11:00") {
+		t.Errorf("Unexpected text %q", text)
+	}
+}
+
+// TestHandlerProviderError verifies provider failures surface as tool errors
+func TestHandlerProviderError(t *testing.T) {
+	h := &toolHandlers{provider: &fakeProvider{accounts: []string{"personal"}, err: errors.New("token expired")}}
+
+	_, _, err := h.handleListCalendars(context.Background(), nil, ListCalendarsInput{Account: "personal"})
+	if err == nil || !strings.Contains(err.Error(), "token expired") {
+		t.Errorf("Expected provider error, got %v", err)
+	}
+}
+
+// TestHandlePartialAccountFailure verifies a failing account does not hide the others
+func TestHandlePartialAccountFailure(t *testing.T) {
+	h, provider := newTestHandlers()
+	provider.accountErrs = map[string]error{"work": errors.New("token expired")}
+
+	res, out, err := h.handleListEvents(context.Background(), nil, ListEventsInput{})
+	if err != nil {
+		t.Fatalf("handleListEvents() error: %v", err)
+	}
+	if len(out.Events) != 1 || out.Events[0].Account != "personal" {
+		t.Errorf("Expected the personal event only, got %+v", out.Events)
+	}
+	if len(out.Errors) != 1 || out.Errors[0].Account != "work" || out.Errors[0].Error != "token expired" {
+		t.Errorf("Expected a work account error, got %+v", out.Errors)
+	}
+	if text := resultText(t, res); !strings.Contains(text, "- [work] token expired") {
+		t.Errorf("Expected failed account in text, got %q", text)
+	}
+
+	_, cals, err := h.handleListCalendars(context.Background(), nil, ListCalendarsInput{})
+	if err != nil {
+		t.Fatalf("handleListCalendars() error: %v", err)
+	}
+	if len(cals.Calendars) != 1 || len(cals.Errors) != 1 {
+		t.Errorf("Expected 1 calendar and 1 error, got %+v", cals)
+	}
+}
+
+// TestHandleCheckAvailabilityPartialFailure verifies a failed account is never reported as free
+func TestHandleCheckAvailabilityPartialFailure(t *testing.T) {
+	h, provider := newTestHandlers()
+	provider.accountErrs = map[string]error{"work": errors.New("token expired")}
+
+	res, out, err := h.handleCheckAvailability(context.Background(), nil, CheckAvailabilityInput{
+		TimeMin: "2026-02-02T00:00:00Z",
+		TimeMax: "2026-02-03T00:00:00Z",
+	})
+	if err != nil {
+		t.Fatalf("handleCheckAvailability() error: %v", err)
+	}
+	if len(out.Errors) != 1 {
+		t.Fatalf("Expected 1 account error, got %+v", out.Errors)
+	}
+	if text := resultText(t, res); strings.Contains(text, "you're free") {
+		t.Errorf("Expected no free claim with a failed account, got %q", text)
+	}
+}
+
 // Helper function
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > 0 && containsHelper(s, substr))
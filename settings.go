@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// settingsWeekdays maps the weekStart setting to the day weeks start on
+var settingsWeekdays = map[string]time.Weekday{
+	"0": time.Sunday,
+	"1": time.Monday,
+	"6": time.Saturday,
+}
+
+// parseSettings reads the well-known settings of an account, keeping every
+// setting as returned by the API alongside
+func parseSettings(account string, settings map[string]string) AccountSettings {
+	parsed := AccountSettings{
+		Account:        account,
+		TimeZone:       settings["timezone"],
+		Locale:         settings["locale"],
+		DateFieldOrder: settings["dateFieldOrder"],
+		Settings:       settings,
+	}
+	if day, ok := settingsWeekdays[settings["weekStart"]]; ok {
+		parsed.WeekStart = day.String()
+	}
+	parsed.Format24HourTime, _ = strconv.ParseBool(settings["format24HourTime"])
+	parsed.HideWeekends, _ = strconv.ParseBool(settings["hideWeekends"])
+	parsed.ShowDeclinedEvents, _ = strconv.ParseBool(settings["showDeclinedEvents"])
+	parsed.DefaultEventLength, _ = strconv.Atoi(settings["defaultEventLength"])
+	if parsed.Settings == nil {
+		parsed.Settings = map[string]string{}
+	}
+	return parsed
+}
+
+// GetSettings returns the Google Calendar settings of the specified account
+// (or all accounts if empty).
+// Accounts that fail are reported in the output's Errors; an error is only
+// returned if no account could be queried.
+func GetSettings(ctx context.Context, provider CalendarProvider, input GetSettingsInput) (GetSettingsOutput, error) {
+	accounts, err := getTargetAccounts(provider, input.Account)
+	if err != nil {
+		return GetSettingsOutput{}, err
+	}
+
+	results, accountErrors, err := forEachAccount(ctx, accounts, func(ctx context.Context, acc string) (AccountSettings, error) {
+		settings, err := provider.Settings(ctx, acc)
+		if err != nil {
+			return AccountSettings{}, err
+		}
+		return parseSettings(acc, settings), nil
+	})
+	if err != nil {
+		return GetSettingsOutput{}, err
+	}
+
+	return GetSettingsOutput{Accounts: results, Errors: accountErrors}, nil
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// FindFreeSlots returns the free slots of at least the requested duration
+// within working hours, across the requested accounts and calendars. Slots are
+// ranked day by day, preferring the roomiest slots of each day.
+func FindFreeSlots(ctx context.Context, provider CalendarProvider, input FindFreeSlotsInput) (FindFreeSlotsOutput, error) {
+	accounts := input.Accounts
+	if len(accounts) == 0 {
+		var err error
+		accounts, err = provider.Accounts()
+		if err != nil {
+			return FindFreeSlotsOutput{}, err
+		}
+	}
+
+	zones, err := resolveUserZones(ctx, provider, accounts, input.TimeZone)
+	if err != nil {
+		return FindFreeSlotsOutput{}, err
+	}
+	loc := zones.display
+
+	timeMin, timeMax, err := resolveTimeRange(input.TimeMin, input.TimeMax, time.Now(), loc, zones.firstDay, endOfDay)
+	if err != nil {
+		return FindFreeSlotsOutput{}, err
+	}
+
+	hours, err := parseWorkingHours(input.WorkdayStart, input.WorkdayEnd)
+	if err != nil {
+		return FindFreeSlotsOutput{}, err
+	}
+
+	duration := time.Duration(input.DurationMinutes) * time.Minute
+	if duration <= 0 {
+		duration = 30 * time.Minute
+	}
+	buffer := time.Duration(max(input.BufferMinutes, 0)) * time.Minute
+
+	maxResults := input.MaxResults
+	if maxResults <= 0 {
+		maxResults = 10
+	}
+	if maxResults > 50 {
+		maxResults = 50
+	}
+
+	busy, err := queryBusyPeriods(ctx, provider, accounts, input.Calendars, len(accounts) != 1, timeMin, timeMax)
+	if err != nil {
+		return FindFreeSlotsOutput{}, err
+	}
+
+	// Widen every busy period by the buffer before merging, so that slots
+	// keep their distance from the surrounding meetings
+	var blocked []BusyPeriod
+	for _, bp := range busy.BusyPeriods {
+		blocked = append(blocked, BusyPeriod{Start: bp.Start.Add(-buffer), End: bp.End.Add(buffer)})
+	}
+	blocked = mergeBusyPeriods(blocked)
+
+	var slots []FreeSlot
+	for _, window := range workingWindows(timeMin.In(loc), timeMax.In(loc), hours, input.IncludeWeekends) {
+		for _, free := range subtractBusy(window, blocked) {
+			if free.End.Sub(free.Start) >= duration {
+				slots = append(slots, FreeSlot{
+					Start:           free.Start,
+					End:             free.End,
+					DurationMinutes: int(free.End.Sub(free.Start).Minutes()),
+				})
+			}
+		}
+	}
+
+	rankFreeSlots(slots)
+	if len(slots) > maxResults {
+		slots = slots[:maxResults]
+	}
+
+	return FindFreeSlotsOutput{
+		Slots:    slots,
+		TimeMin:  timeMin,
+		TimeMax:  timeMax,
+		TimeZone: loc.String(),
+		Errors:   busy.Errors,
+	}, nil
+}
+
+// mergeBusyPeriods returns the union of the periods as a sorted list of
+// non-overlapping periods; touching periods are joined
+func mergeBusyPeriods(periods []BusyPeriod) []BusyPeriod {
+	if len(periods) == 0 {
+		return nil
+	}
+
+	sorted := slices.Clone(periods)
+	slices.SortFunc(sorted, func(a, b BusyPeriod) int {
+		return cmp.Or(a.Start.Compare(b.Start), a.End.Compare(b.End))
+	})
+
+	merged := []BusyPeriod{sorted[0]}
+	for _, bp := range sorted[1:] {
+		last := &merged[len(merged)-1]
+		if bp.Start.After(last.End) {
+			merged = append(merged, bp)
+			continue
+		}
+		if bp.End.After(last.End) {
+			last.End = bp.End
+		}
+	}
+	return merged
+}
+
+// workingHours is a daily time window expressed as offsets from midnight
+type workingHours struct {
+	start, end time.Duration
+}
+
+// parseWorkingHours parses HH:MM bounds, defaulting to 09:00-17:00
+func parseWorkingHours(start, end string) (workingHours, error) {
+	parse := func(name, value, fallback string) (time.Duration, error) {
+		if value == "" {
+			value = fallback
+		}
+		t, err := time.Parse("15:04", value)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s format (expected HH:MM): %w", name, err)
+		}
+		return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+	}
+
+	s, err := parse("workday_start", start, "09:00")
+	if err != nil {
+		return workingHours{}, err
+	}
+	e, err := parse("workday_end", end, "17:00")
+	if err != nil {
+		return workingHours{}, err
+	}
+	if e <= s {
+		return workingHours{}, fmt.Errorf("workday_end must be after workday_start")
+	}
+	return workingHours{start: s, end: e}, nil
+}
+
+// workingWindows returns the working hours of every day between timeMin and
+// timeMax, clipped to that range. Days are computed in timeMin's location.
+func workingWindows(timeMin, timeMax time.Time, hours workingHours, includeWeekends bool) []BusyPeriod {
+	loc := timeMin.Location()
+	var windows []BusyPeriod
+
+	y, m, d := timeMin.Date()
+	for day := time.Date(y, m, d, 0, 0, 0, 0, loc); day.Before(timeMax); day = day.AddDate(0, 0, 1) {
+		if !includeWeekends && (day.Weekday() == time.Saturday || day.Weekday() == time.Sunday) {
+			continue
+		}
+
+		start := atClock(day, hours.start)
+		end := atClock(day, hours.end)
+
+		if start.Before(timeMin) {
+			start = timeMin
+		}
+		if end.After(timeMax) {
+			end = timeMax
+		}
+		if end.After(start) {
+			windows = append(windows, BusyPeriod{Start: start, End: end})
+		}
+	}
+	return windows
+}
+
+// atClock returns the wall-clock time offset from midnight on the given day,
+// so that working hours stay put across DST changes
+func atClock(day time.Time, offset time.Duration) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), int(offset/time.Hour), int(offset%time.Hour/time.Minute), 0, 0, day.Location())
+}
+
+// subtractBusy returns the parts of window not covered by the sorted,
+// non-overlapping busy periods
+func subtractBusy(window BusyPeriod, busy []BusyPeriod) []BusyPeriod {
+	var free []BusyPeriod
+	cursor := window.Start
+	for _, bp := range busy {
+		if !bp.End.After(cursor) {
+			continue
+		}
+		if !bp.Start.Before(window.End) {
+			break
+		}
+		if bp.Start.After(cursor) {
+			free = append(free, BusyPeriod{Start: cursor, End: bp.Start.In(cursor.Location())})
+		}
+		cursor = bp.End.In(cursor.Location())
+	}
+	if window.End.After(cursor) {
+		free = append(free, BusyPeriod{Start: cursor, End: window.End})
+	}
+	return free
+}
+
+// rankFreeSlots orders slots day by day and, within a day, from the longest
+// to the shortest, then numbers them
+func rankFreeSlots(slots []FreeSlot) {
+	slices.SortStableFunc(slots, func(a, b FreeSlot) int {
+		ay, am, ad := a.Start.Date()
+		by, bm, bd := b.Start.Date()
+		return cmp.Or(
+			cmp.Compare(ay, by),
+			cmp.Compare(am, bm),
+			cmp.Compare(ad, bd),
+			cmp.Compare(b.DurationMinutes, a.DurationMinutes),
+			a.Start.Compare(b.Start),
+		)
+	})
+	for i := range slots {
+		slots[i].Rank = i + 1
+	}
+}
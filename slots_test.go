@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func utcAt(day, hour, minute int) time.Time {
+	return time.Date(2026, 2, day, hour, minute, 0, 0, time.UTC)
+}
+
+// TestMergeBusyPeriods verifies overlapping and touching periods are coalesced
+func TestMergeBusyPeriods(t *testing.T) {
+	merged := mergeBusyPeriods([]BusyPeriod{
+		{Start: utcAt(2, 14, 0), End: utcAt(2, 15, 0)},
+		{Start: utcAt(2, 9, 0), End: utcAt(2, 10, 0)},
+		{Start: utcAt(2, 9, 30), End: utcAt(2, 9, 45)},
+		{Start: utcAt(2, 10, 0), End: utcAt(2, 11, 0)},
+		{Start: utcAt(2, 14, 30), End: utcAt(2, 16, 0)},
+	})
+
+	want := []BusyPeriod{
+		{Start: utcAt(2, 9, 0), End: utcAt(2, 11, 0)},
+		{Start: utcAt(2, 14, 0), End: utcAt(2, 16, 0)},
+	}
+	if len(merged) != len(want) {
+		t.Fatalf("Expected %d periods, got %+v", len(want), merged)
+	}
+	for i := range want {
+		if !merged[i].Start.Equal(want[i].Start) || !merged[i].End.Equal(want[i].End) {
+			t.Errorf("Period %d: expected %v-%v, got %v-%v", i, want[i].Start, want[i].End, merged[i].Start, merged[i].End)
+		}
+	}
+}
+
+// TestWorkingWindows verifies weekends are skipped and DST keeps wall-clock hours
+func TestWorkingWindows(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	hours := workingHours{start: 9 * time.Hour, end: 17 * time.Hour}
+
+	// Friday 2026-03-27 to Monday 2026-03-30 spans the switch to summer time
+	windows := workingWindows(
+		time.Date(2026, 3, 27, 12, 0, 0, 0, paris),
+		time.Date(2026, 3, 31, 0, 0, 0, 0, paris),
+		hours, false,
+	)
+	if len(windows) != 2 {
+		t.Fatalf("Expected Friday and Monday, got %+v", windows)
+	}
+	if windows[0].Start.Hour() != 12 || windows[0].End.Hour() != 17 {
+		t.Errorf("Expected Friday clipped to 12:00-17:00, got %v-%v", windows[0].Start, windows[0].End)
+	}
+	if windows[1].Start.Hour() != 9 || windows[1].Start.Weekday() != time.Monday {
+		t.Errorf("Expected Monday 09:00, got %v", windows[1].Start)
+	}
+	if _, offset := windows[1].Start.Zone(); offset != 2*3600 {
+		t.Errorf("Expected summer time offset on Monday, got %d", offset)
+	}
+}
+
+// TestFindFreeSlots verifies busy periods of all accounts, buffers and minimum duration
+func TestFindFreeSlots(t *testing.T) {
+	provider := &fakeProvider{
+		accounts: []string{"personal", "work"},
+		busy: map[string][]BusyPeriod{
+			"work":     {{Start: utcAt(2, 10, 0), End: utcAt(2, 11, 0), Account: "work"}},
+			"personal": {{Start: utcAt(2, 10, 30), End: utcAt(2, 12, 0), Account: "personal"}, {Start: utcAt(3, 9, 0), End: utcAt(3, 16, 0)}},
+		},
+	}
+
+	output, err := FindFreeSlots(context.Background(), provider, FindFreeSlotsInput{
+		TimeMin:         "2026-02-02T00:00:00Z",
+		TimeMax:         "2026-02-04T00:00:00Z",
+		DurationMinutes: 60,
+		BufferMinutes:   15,
+		TimeZone:        "UTC",
+	})
+	if err != nil {
+		t.Fatalf("FindFreeSlots() error: %v", err)
+	}
+
+	// Monday: 09:00-09:45 is too short, 12:15-17:00 fits; Tuesday: 16:15-17:00 is too short
+	if len(output.Slots) != 1 {
+		t.Fatalf("Expected 1 slot, got %+v", output.Slots)
+	}
+	slot := output.Slots[0]
+	if !slot.Start.Equal(utcAt(2, 12, 15)) || !slot.End.Equal(utcAt(2, 17, 0)) || slot.DurationMinutes != 285 || slot.Rank != 1 {
+		t.Errorf("Unexpected slot %+v", slot)
+	}
+}
+
+// TestFindFreeSlotsRanking verifies slots are ranked per day, longest first
+func TestFindFreeSlotsRanking(t *testing.T) {
+	provider := &fakeProvider{
+		accounts: []string{"work"},
+		busy: map[string][]BusyPeriod{
+			"work": {{Start: utcAt(6, 10, 0), End: utcAt(6, 15, 0)}},
+		},
+	}
+
+	output, err := FindFreeSlots(context.Background(), provider, FindFreeSlotsInput{
+		TimeMin:  "2026-02-06T00:00:00Z",
+		TimeMax:  "2026-02-10T00:00:00Z",
+		TimeZone: "UTC",
+	})
+	if err != nil {
+		t.Fatalf("FindFreeSlots() error: %v", err)
+	}
+
+	// Friday 15:00-17:00 beats Friday 09:00-10:00; the weekend is skipped
+	want := []time.Time{utcAt(6, 15, 0), utcAt(6, 9, 0), utcAt(9, 9, 0)}
+	if len(output.Slots) != len(want) {
+		t.Fatalf("Expected %d slots, got %+v", len(want), output.Slots)
+	}
+	for i, start := range want {
+		if !output.Slots[i].Start.Equal(start) || output.Slots[i].Rank != i+1 {
+			t.Errorf("Slot %d: expected rank %d at %v, got %+v", i, i+1, start, output.Slots[i])
+		}
+	}
+}
+
+// TestFindFreeSlotsInvalidInput verifies malformed working hours and zones are rejected
+func TestFindFreeSlotsInvalidInput(t *testing.T) {
+	provider := &fakeProvider{accounts: []string{"work"}}
+	base := FindFreeSlotsInput{TimeMin: "2026-02-02T00:00:00Z", TimeMax: "2026-02-03T00:00:00Z"}
+
+	for name, mutate := range map[string]func(*FindFreeSlotsInput){
+		"bad zone":       func(in *FindFreeSlotsInput) { in.TimeZone = "Mars/Olympus" },
+		"bad hours":      func(in *FindFreeSlotsInput) { in.WorkdayStart = "9am" },
+		"inverted hours": func(in *FindFreeSlotsInput) { in.WorkdayStart, in.WorkdayEnd = "18:00", "09:00" },
+		"empty range":    func(in *FindFreeSlotsInput) { in.TimeMax = in.TimeMin },
+	} {
+		t.Run(name, func(t *testing.T) {
+			input := base
+			mutate(&input)
+			if _, err := FindFreeSlots(context.Background(), provider, input); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
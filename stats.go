@@ -0,0 +1,247 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// maxStatsEvents bounds the events read to compute statistics
+const maxStatsEvents = 5000
+
+// CalendarStats measures the meeting load over a time range, this week by
+// default: time in meetings per day and week, one-on-one and group meetings,
+// focus time left within working hours, the longest meeting-free block, and
+// who and which calendars the meetings are with. Meetings are the busy events
+// with at least one other attendee who did not decline.
+func CalendarStats(ctx context.Context, provider CalendarProvider, input CalendarStatsInput) (CalendarStatsOutput, error) {
+	hours, err := parseWorkingHours(input.WorkdayStart, input.WorkdayEnd)
+	if err != nil {
+		return CalendarStatsOutput{}, err
+	}
+
+	minFocus := input.MinFocusMinutes
+	if minFocus <= 0 {
+		minFocus = 30
+	}
+	maxCollaborators := input.MaxCollaborators
+	if maxCollaborators <= 0 {
+		maxCollaborators = 5
+	}
+	if maxCollaborators > 50 {
+		maxCollaborators = 50
+	}
+
+	targets, err := getTargetAccounts(provider, input.Account)
+	if err != nil {
+		return CalendarStatsOutput{}, err
+	}
+	zones, err := resolveUserZones(ctx, provider, targets, input.TimeZone)
+	if err != nil {
+		return CalendarStatsOutput{}, err
+	}
+
+	timeMin := input.TimeMin
+	if timeMin == "" && input.TimeMax == "" {
+		timeMin = "this week"
+	}
+	events, err := listAllEvents(ctx, provider, ListEventsInput{
+		Account:         input.Account,
+		CalendarIDs:     input.Calendars,
+		AllCalendars:    len(input.Calendars) == 0,
+		TimeMin:         timeMin,
+		TimeMax:         input.TimeMax,
+		ExcludeDeclined: true,
+	}, targets, zones, maxStatsEvents)
+	if err != nil {
+		return CalendarStatsOutput{}, err
+	}
+	rangeMin, rangeMax := events.TimeMin, events.TimeMax
+	loc := rangeMin.Location()
+
+	output := CalendarStatsOutput{
+		TimeMin:      rangeMin,
+		TimeMax:      rangeMax,
+		TimeZone:     events.TimeZone,
+		WorkingHours: fmt.Sprintf("%s-%s", atClock(rangeMin, hours.start).Format("15:04"), atClock(rangeMin, hours.end).Format("15:04")),
+		Truncated:    events.Truncated,
+		Errors:       events.Errors,
+	}
+
+	var busy, meetings []BusyPeriod
+	var meetingStarts []time.Time
+	calendars := make(map[[2]string]*MeetingLoad)
+	accounts := make(map[string]*MeetingLoad)
+	collaborators := make(map[string]*CollaboratorStats)
+	for _, ev := range busyEvents(events.Events, false) {
+		period, ok := clipPeriod(ev.Start, ev.End, rangeMin, rangeMax)
+		if !ok {
+			continue
+		}
+		busy = append(busy, period)
+
+		guests := meetingGuests(ev)
+		switch len(guests) {
+		case 0:
+			output.SoloEvents++
+			continue
+		case 1:
+			output.OneOnOneMeetings++
+		default:
+			output.GroupMeetings++
+		}
+		output.Meetings++
+		meetings = append(meetings, period)
+		meetingStarts = append(meetingStarts, period.Start)
+
+		minutes := int(period.End.Sub(period.Start).Minutes())
+		addLoad := func(load *MeetingLoad) {
+			load.Meetings++
+			load.Minutes += minutes
+		}
+		calKey := [2]string{ev.Account, ev.CalendarID}
+		if calendars[calKey] == nil {
+			calendars[calKey] = &MeetingLoad{Account: ev.Account, CalendarID: ev.CalendarID, CalendarName: ev.CalendarName}
+		}
+		addLoad(calendars[calKey])
+		if accounts[ev.Account] == nil {
+			accounts[ev.Account] = &MeetingLoad{Account: ev.Account}
+		}
+		addLoad(accounts[ev.Account])
+		for _, guest := range guests {
+			email := strings.ToLower(guest.Email)
+			if collaborators[email] == nil {
+				collaborators[email] = &CollaboratorStats{Email: guest.Email}
+			}
+			c := collaborators[email]
+			c.DisplayName = cmp.Or(c.DisplayName, guest.DisplayName)
+			c.Meetings++
+			c.Minutes += minutes
+		}
+	}
+	busy = mergeBusyPeriods(busy)
+	meetings = mergeBusyPeriods(meetings)
+
+	for day := startOfDay(rangeMin.In(loc)); day.Before(rangeMax); day = day.AddDate(0, 0, 1) {
+		period, _ := clipPeriod(day, day.AddDate(0, 0, 1), rangeMin, rangeMax)
+		stats := DayStats{
+			Date:           day.Format("2006-01-02"),
+			Weekday:        day.Weekday().String(),
+			MeetingMinutes: overlapMinutes(meetings, period),
+		}
+		for _, start := range meetingStarts {
+			if !start.Before(period.Start) && start.Before(period.End) {
+				stats.Meetings++
+			}
+		}
+
+		for _, window := range workingWindows(period.Start, period.End, hours, input.IncludeWeekends) {
+			for _, free := range subtractBusy(window, busy) {
+				minutes := int(free.End.Sub(free.Start).Minutes())
+				if minutes >= minFocus {
+					stats.FocusMinutes += minutes
+				}
+				stats.LongestFreeMinutes = max(stats.LongestFreeMinutes, minutes)
+				if output.LongestFreeBlock == nil || minutes > output.LongestFreeBlock.DurationMinutes {
+					output.LongestFreeBlock = &FreeBlock{Start: free.Start, End: free.End, DurationMinutes: minutes}
+				}
+			}
+		}
+
+		output.MeetingMinutes += stats.MeetingMinutes
+		output.FocusMinutes += stats.FocusMinutes
+		output.Days = append(output.Days, stats)
+
+		year, week := day.ISOWeek()
+		name := fmt.Sprintf("%d-W%02d", year, week)
+		if len(output.Weeks) == 0 || output.Weeks[len(output.Weeks)-1].Week != name {
+			output.Weeks = append(output.Weeks, WeekStats{Week: name})
+		}
+		w := &output.Weeks[len(output.Weeks)-1]
+		w.Meetings += stats.Meetings
+		w.MeetingMinutes += stats.MeetingMinutes
+		w.FocusMinutes += stats.FocusMinutes
+	}
+
+	for _, c := range collaborators {
+		output.Collaborators = append(output.Collaborators, *c)
+	}
+	slices.SortFunc(output.Collaborators, func(a, b CollaboratorStats) int {
+		return cmp.Or(cmp.Compare(b.Minutes, a.Minutes), cmp.Compare(b.Meetings, a.Meetings), strings.Compare(a.Email, b.Email))
+	})
+	if len(output.Collaborators) > maxCollaborators {
+		output.Collaborators = output.Collaborators[:maxCollaborators]
+	}
+	output.Calendars = sortedLoads(calendars)
+	output.Accounts = sortedLoads(accounts)
+
+	return output, nil
+}
+
+// meetingGuests returns the people other than the user attending an event,
+// leaving out rooms and guests who declined
+func meetingGuests(ev Event) []Attendee {
+	var guests []Attendee
+	for _, att := range ev.Attendees {
+		if att.Self || att.Resource || att.ResponseStatus == "declined" {
+			continue
+		}
+		guests = append(guests, att)
+	}
+	return guests
+}
+
+// clipPeriod returns the part of start-end within timeMin-timeMax, reporting
+// whether it is not empty
+func clipPeriod(start, end, timeMin, timeMax time.Time) (BusyPeriod, bool) {
+	if start.Before(timeMin) {
+		start = timeMin
+	}
+	if end.After(timeMax) {
+		end = timeMax
+	}
+	return BusyPeriod{Start: start, End: end}, end.After(start)
+}
+
+// overlapMinutes returns how many minutes of the window the sorted,
+// non-overlapping periods cover
+func overlapMinutes(periods []BusyPeriod, window BusyPeriod) int {
+	var total time.Duration
+	for _, p := range periods {
+		if clipped, ok := clipPeriod(p.Start, p.End, window.Start, window.End); ok {
+			total += clipped.End.Sub(clipped.Start)
+		}
+	}
+	return int(total.Minutes())
+}
+
+// sortedLoads returns the loads from the busiest to the least busy
+func sortedLoads[K comparable](loads map[K]*MeetingLoad) []MeetingLoad {
+	var sorted []MeetingLoad
+	for _, load := range loads {
+		sorted = append(sorted, *load)
+	}
+	slices.SortFunc(sorted, func(a, b MeetingLoad) int {
+		return cmp.Or(
+			cmp.Compare(b.Minutes, a.Minutes),
+			strings.Compare(a.Account, b.Account),
+			strings.Compare(a.CalendarID, b.CalendarID),
+		)
+	})
+	return sorted
+}
+
+// formatMinutes renders a number of minutes as hours and minutes, e.g. "8h 30m"
+func formatMinutes(minutes int) string {
+	switch {
+	case minutes < 60:
+		return fmt.Sprintf("%dm", minutes)
+	case minutes%60 == 0:
+		return fmt.Sprintf("%dh", minutes/60)
+	default:
+		return fmt.Sprintf("%dh %dm", minutes/60, minutes%60)
+	}
+}
@@ -0,0 +1,24 @@
+package main
+
+import "testing"
+
+// TestFormatMinutes verifies minutes are shown as hours and minutes
+func TestFormatMinutes(t *testing.T) {
+	tests := map[int]string{0: "0m", 45: "45m", 60: "1h", 75: "1h 15m", 1500: "25h"}
+	for minutes, want := range tests {
+		if got := formatMinutes(minutes); got != want {
+			t.Errorf("formatMinutes(%d) = %q, want %q", minutes, got, want)
+		}
+	}
+}
+
+// TestOverlapMinutes verifies only the part of periods within the window counts
+func TestOverlapMinutes(t *testing.T) {
+	periods := []BusyPeriod{
+		{Start: utcAt(2, 23, 0), End: utcAt(3, 1, 0)},
+		{Start: utcAt(3, 9, 0), End: utcAt(3, 9, 30)},
+	}
+	if got := overlapMinutes(periods, BusyPeriod{Start: utcAt(3, 0, 0), End: utcAt(4, 0, 0)}); got != 90 {
+		t.Errorf("Expected 90 minutes, got %d", got)
+	}
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeSpan is the period a time expression names, such as a day or a week.
+// Expressions naming an instant have an equal Start and End.
+type timeSpan struct {
+	Start, End time.Time
+}
+
+var (
+	isoWeekPattern  = regexp.MustCompile(`^(?:(\d{4})-?)?w(\d{1,2})$`)
+	durationPattern = regexp.MustCompile(`^([+-])\s*(\d+)\s*(m|min|mins|h|hr|hrs|d|day|days|w|wk|week|weeks)$`)
+)
+
+// Parts of the day, as offsets from midnight
+var dayParts = map[string][2]time.Duration{
+	"morning":   {6 * time.Hour, 12 * time.Hour},
+	"afternoon": {12 * time.Hour, 18 * time.Hour},
+	"evening":   {18 * time.Hour, 22 * time.Hour},
+	"night":     {18 * time.Hour, 24 * time.Hour},
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday,
+	"thursday": time.Thursday, "friday": time.Friday, "saturday": time.Saturday,
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// resolveTimeRange resolves the time_min and time_max expressions of a tool
+// call in the user's time zone, with weeks starting on firstDay. An empty
+// time_min means now. An empty
+// time_max ends the period named by time_min ("tomorrow"), or else defaults to
+// defaultMax(timeMin), which may return the zero time to leave the range open.
+// Durations count from now in time_min and from time_min in time_max.
+func resolveTimeRange(minExpr, maxExpr string, now time.Time, loc *time.Location, firstDay time.Weekday, defaultMax func(time.Time) time.Time) (time.Time, time.Time, error) {
+	minSpan := timeSpan{Start: now.In(loc), End: now.In(loc)}
+	if strings.TrimSpace(minExpr) != "" {
+		span, err := parseTimeExpr(minExpr, now, now, loc, firstDay)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid time_min format: %w", err)
+		}
+		minSpan = span
+	}
+	timeMin := minSpan.Start
+
+	var timeMax time.Time
+	switch {
+	case strings.TrimSpace(maxExpr) != "":
+		span, err := parseTimeExpr(maxExpr, now, timeMin, loc, firstDay)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid time_max format: %w", err)
+		}
+		timeMax = span.End
+	case minSpan.End.After(minSpan.Start):
+		timeMax = minSpan.End
+	default:
+		timeMax = defaultMax(timeMin)
+	}
+
+	if !timeMax.IsZero() && !timeMax.After(timeMin) {
+		return time.Time{}, time.Time{}, fmt.Errorf("time_max (%s) must be after time_min (%s)",
+			timeMax.Format(time.RFC3339), timeMin.Format(time.RFC3339))
+	}
+	return timeMin, timeMax, nil
+}
+
+// parseTimeExpr parses an absolute or relative time expression in loc:
+// RFC3339 timestamps, local date-times ("2026-02-03 14:00"), dates, "now",
+// "today", "tomorrow", "yesterday", parts of days ("this afternoon",
+// "tomorrow morning", "tonight"), weekdays ("friday", "next monday"), weeks
+// and months ("next week", "this month", "2026-W07"), and signed durations
+// ("+3d", "-2h") counted from anchor. Weeks start on firstDay, except ISO
+// weeks, which start on Monday.
+func parseTimeExpr(expr string, now, anchor time.Time, loc *time.Location, firstDay time.Weekday) (timeSpan, error) {
+	raw := strings.TrimSpace(expr)
+	s := strings.ToLower(strings.Join(strings.Fields(raw), " "))
+	now = now.In(loc)
+	today := startOfDay(now)
+
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return instant(t.In(loc)), nil
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02 15:04:05", "2006-01-02 15:04"} {
+		if t, err := time.ParseInLocation(layout, raw, loc); err == nil {
+			return instant(t), nil
+		}
+	}
+	if t, err := time.ParseInLocation("2006-01-02", raw, loc); err == nil {
+		return days(t, 1), nil
+	}
+
+	if m := isoWeekPattern.FindStringSubmatch(s); m != nil {
+		year := now.Year()
+		if m[1] != "" {
+			year, _ = strconv.Atoi(m[1])
+		}
+		week, _ := strconv.Atoi(m[2])
+		monday, ok := isoWeekStart(year, week, loc)
+		if !ok {
+			return timeSpan{}, fmt.Errorf("week %d does not exist in %d", week, year)
+		}
+		return days(monday, 7), nil
+	}
+
+	if m := durationPattern.FindStringSubmatch(s); m != nil {
+		n, err := strconv.Atoi(m[2])
+		if err != nil {
+			return timeSpan{}, fmt.Errorf("invalid duration '%s': %w", raw, err)
+		}
+		if m[1] == "-" {
+			n = -n
+		}
+		anchor = anchor.In(loc)
+		switch m[3][0] {
+		case 'm':
+			return instant(anchor.Add(time.Duration(n) * time.Minute)), nil
+		case 'h':
+			return instant(anchor.Add(time.Duration(n) * time.Hour)), nil
+		case 'd':
+			return instant(anchor.AddDate(0, 0, n)), nil
+		default:
+			return instant(anchor.AddDate(0, 0, 7*n)), nil
+		}
+	}
+
+	switch s {
+	case "now":
+		return instant(now), nil
+	case "today":
+		return days(today, 1), nil
+	case "tomorrow":
+		return days(today.AddDate(0, 0, 1), 1), nil
+	case "yesterday":
+		return days(today.AddDate(0, 0, -1), 1), nil
+	case "tonight":
+		return dayPart(today, "night"), nil
+	case "this week", "next week", "last week":
+		return days(weekStart(today, firstDay).AddDate(0, 0, 7*relativeOffset(s)), 7), nil
+	case "this weekend", "weekend", "next weekend":
+		saturday := weekStart(today, time.Monday).AddDate(0, 0, 5+7*relativeOffset(s))
+		return days(saturday, 2), nil
+	case "this month", "next month", "last month":
+		first := time.Date(today.Year(), today.Month()+time.Month(relativeOffset(s)), 1, 0, 0, 0, 0, loc)
+		return timeSpan{Start: first, End: first.AddDate(0, 1, 0)}, nil
+	}
+
+	if day, part, ok := strings.Cut(s, " "); ok {
+		if _, isPart := dayParts[part]; isPart {
+			switch day {
+			case "this", "today":
+				return dayPart(today, part), nil
+			case "tomorrow":
+				return dayPart(today.AddDate(0, 0, 1), part), nil
+			case "yesterday":
+				return dayPart(today.AddDate(0, 0, -1), part), nil
+			}
+		}
+	}
+
+	// Weekdays: the next one (today included), the one of next week, or the last one
+	qualifier, name, ok := strings.Cut(s, " ")
+	if !ok {
+		qualifier, name = "", s
+	}
+	if wd, isWeekday := weekdays[name]; isWeekday {
+		switch qualifier {
+		case "", "this":
+			return days(today.AddDate(0, 0, (int(wd)-int(today.Weekday())+7)%7), 1), nil
+		case "next":
+			return days(weekStart(today, firstDay).AddDate(0, 0, 7+(int(wd)-int(firstDay)+7)%7), 1), nil
+		case "last":
+			return days(today.AddDate(0, 0, -((int(today.Weekday())-int(wd)+6)%7+1)), 1), nil
+		}
+	}
+
+	return timeSpan{}, fmt.Errorf("cannot parse '%s' (expected RFC3339, a date such as 2026-02-03, today, tomorrow, "+
+		"this afternoon, friday, next week, an ISO week such as 2026-W07, or a duration such as +3d)", raw)
+}
+
+// endOfDay returns the midnight ending the day of t, the default end of
+// ranges starting at an instant in tools without a longer default
+func endOfDay(t time.Time) time.Time {
+	return startOfDay(t).AddDate(0, 0, 1)
+}
+
+// instant returns the span of a single point in time
+func instant(t time.Time) timeSpan {
+	return timeSpan{Start: t, End: t}
+}
+
+// days returns the span of n whole days from the given midnight
+func days(midnight time.Time, n int) timeSpan {
+	return timeSpan{Start: midnight, End: midnight.AddDate(0, 0, n)}
+}
+
+// dayPart returns the span of a part of the day, such as "afternoon"
+func dayPart(midnight time.Time, part string) timeSpan {
+	bounds := dayParts[part]
+	return timeSpan{Start: atClock(midnight, bounds[0]), End: atClock(midnight, bounds[1])}
+}
+
+// relativeOffset returns -1, 0 or 1 for expressions starting with last, this or next
+func relativeOffset(s string) int {
+	switch {
+	case strings.HasPrefix(s, "next "):
+		return 1
+	case strings.HasPrefix(s, "last "):
+		return -1
+	default:
+		return 0
+	}
+}
+
+// startOfDay returns midnight of the day of t in t's location
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// weekStart returns the midnight starting the week of the given midnight,
+// for weeks starting on firstDay
+func weekStart(midnight time.Time, firstDay time.Weekday) time.Time {
+	return midnight.AddDate(0, 0, -((int(midnight.Weekday()) - int(firstDay) + 7) % 7))
+}
+
+// isoWeekStart returns the Monday of an ISO 8601 week, reporting whether the
+// week exists in that year
+func isoWeekStart(year, week int, loc *time.Location) (time.Time, bool) {
+	// January 4th is always in the first week
+	monday := weekStart(time.Date(year, time.January, 4, 0, 0, 0, 0, loc), time.Monday).AddDate(0, 0, 7*(week-1))
+	y, w := monday.ISOWeek()
+	return monday, week >= 1 && y == year && w == week
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestParseTimeExpr verifies absolute and relative expressions against a fixed now
+func TestParseTimeExpr(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	// Wednesday
+	now := time.Date(2026, 2, 4, 10, 30, 0, 0, paris)
+	at := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2026, month, day, hour, minute, 0, 0, paris)
+	}
+
+	tests := []struct {
+		expr       string
+		start, end time.Time
+	}{
+		{"2026-02-03T10:00:00Z", at(2, 3, 11, 0), at(2, 3, 11, 0)},
+		{"2026-02-03", at(2, 3, 0, 0), at(2, 4, 0, 0)},
+		{"2026-02-03 14:00", at(2, 3, 14, 0), at(2, 3, 14, 0)},
+		{"now", now, now},
+		{"Today", at(2, 4, 0, 0), at(2, 5, 0, 0)},
+		{"tomorrow", at(2, 5, 0, 0), at(2, 6, 0, 0)},
+		{"yesterday", at(2, 3, 0, 0), at(2, 4, 0, 0)},
+		{"this afternoon", at(2, 4, 12, 0), at(2, 4, 18, 0)},
+		{"tomorrow  morning", at(2, 5, 6, 0), at(2, 5, 12, 0)},
+		{"tonight", at(2, 4, 18, 0), at(2, 5, 0, 0)},
+		{"this week", at(2, 2, 0, 0), at(2, 9, 0, 0)},
+		{"next week", at(2, 9, 0, 0), at(2, 16, 0, 0)},
+		{"last week", at(1, 26, 0, 0), at(2, 2, 0, 0)},
+		{"this weekend", at(2, 7, 0, 0), at(2, 9, 0, 0)},
+		{"this month", at(2, 1, 0, 0), at(3, 1, 0, 0)},
+		{"2026-W07", at(2, 9, 0, 0), at(2, 16, 0, 0)},
+		{"w6", at(2, 2, 0, 0), at(2, 9, 0, 0)},
+		{"friday", at(2, 6, 0, 0), at(2, 7, 0, 0)},
+		{"wednesday", at(2, 4, 0, 0), at(2, 5, 0, 0)},
+		{"next monday", at(2, 9, 0, 0), at(2, 10, 0, 0)},
+		{"last wednesday", at(1, 28, 0, 0), at(1, 29, 0, 0)},
+		{"+3d", at(2, 7, 10, 30), at(2, 7, 10, 30)},
+		{"-2h", at(2, 4, 8, 30), at(2, 4, 8, 30)},
+		{"+1w", at(2, 11, 10, 30), at(2, 11, 10, 30)},
+		{"+45 min", at(2, 4, 11, 15), at(2, 4, 11, 15)},
+		// The day summer time starts lasts 23 hours
+		{"2026-03-29", at(3, 29, 0, 0), at(3, 30, 0, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			span, err := parseTimeExpr(tt.expr, now, now, paris, time.Monday)
+			if err != nil {
+				t.Fatalf("parseTimeExpr() error: %v", err)
+			}
+			if !span.Start.Equal(tt.start) || !span.End.Equal(tt.end) {
+				t.Errorf("Expected %v - %v, got %v - %v", tt.start, tt.end, span.Start, span.End)
+			}
+			if span.Start.Location() != paris {
+				t.Errorf("Expected a time in Europe/Paris, got %v", span.Start.Location())
+			}
+		})
+	}
+
+	for _, expr := range []string{"tomorrow-ish", "2026-W54", "next fortnight", "+3 years"} {
+		if _, err := parseTimeExpr(expr, now, now, paris, time.Monday); err == nil {
+			t.Errorf("Expected %q to be rejected", expr)
+		}
+	}
+}
+
+// TestParseTimeExprWeekStart verifies weeks follow the first day of the week
+// of the user's settings, except ISO weeks
+func TestParseTimeExprWeekStart(t *testing.T) {
+	// Wednesday
+	now := time.Date(2026, 2, 4, 10, 30, 0, 0, time.UTC)
+	day := func(month time.Month, d int) time.Time { return time.Date(2026, month, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		expr       string
+		firstDay   time.Weekday
+		start, end time.Time
+	}{
+		{"this week", time.Sunday, day(2, 1), day(2, 8)},
+		{"next week", time.Saturday, day(2, 7), day(2, 14)},
+		{"next sunday", time.Sunday, day(2, 8), day(2, 9)},
+		{"next sunday", time.Monday, day(2, 15), day(2, 16)},
+		{"this weekend", time.Sunday, day(2, 7), day(2, 9)},
+		{"2026-W07", time.Sunday, day(2, 9), day(2, 16)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr+" from "+tt.firstDay.String(), func(t *testing.T) {
+			span, err := parseTimeExpr(tt.expr, now, now, time.UTC, tt.firstDay)
+			if err != nil {
+				t.Fatalf("parseTimeExpr() error: %v", err)
+			}
+			if !span.Start.Equal(tt.start) || !span.End.Equal(tt.end) {
+				t.Errorf("Expected %v - %v, got %v - %v", tt.start, tt.end, span.Start, span.End)
+			}
+		})
+	}
+}
+
+// TestResolveTimeRange verifies defaults and how time_max relates to time_min
+func TestResolveTimeRange(t *testing.T) {
+	now := time.Date(2026, 2, 4, 10, 30, 0, 0, time.UTC)
+	week := func(t time.Time) time.Time { return t.AddDate(0, 0, 7) }
+	day := func(d, hour int) time.Time { return time.Date(2026, 2, d, hour, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name, min, max string
+		wantMin        time.Time
+		wantMax        time.Time
+	}{
+		{"defaults", "", "", now, now.AddDate(0, 0, 7)},
+		{"period without time_max", "tomorrow", "", day(5, 0), day(6, 0)},
+		{"instant without time_max", "2026-02-05T09:00:00Z", "", day(5, 9), day(12, 9)},
+		{"time_max ends its period", "today", "friday", day(4, 0), day(7, 0)},
+		{"duration from time_min", "tomorrow", "+3d", day(5, 0), day(8, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timeMin, timeMax, err := resolveTimeRange(tt.min, tt.max, now, time.UTC, time.Monday, week)
+			if err != nil {
+				t.Fatalf("resolveTimeRange() error: %v", err)
+			}
+			if !timeMin.Equal(tt.wantMin) || !timeMax.Equal(tt.wantMax) {
+				t.Errorf("Expected %v - %v, got %v - %v", tt.wantMin, tt.wantMax, timeMin, timeMax)
+			}
+		})
+	}
+
+	if _, _, err := resolveTimeRange("tomorrow", "today", now, time.UTC, time.Monday, week); err == nil || !strings.Contains(err.Error(), "must be after") {
+		t.Errorf("Expected an inverted range error, got %v", err)
+	}
+	if _, _, err := resolveTimeRange("", "soonish", now, time.UTC, time.Monday, week); err == nil || !strings.Contains(err.Error(), "invalid time_max format") {
+		t.Errorf("Expected an invalid time_max error, got %v", err)
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// userZones holds the time zones a tool call works in, and the day its weeks
+// start on
+type userZones struct {
+	// display is the zone times are shown in and day boundaries computed in
+	display *time.Location
+
+	// accounts is the zone of each account, used to anchor all-day dates
+	accounts map[string]*time.Location
+
+	// firstDay is the first day of the week, from the Google Calendar
+	// settings of the first account that has one, else Monday
+	firstDay time.Weekday
+}
+
+// resolveUserZones returns the time zones of a tool call. An explicit
+// override applies to every account. Otherwise each account uses its
+// configured time_zone, else the time zone of its Google Calendar settings,
+// and times are shown in the zone of the first account that has one; accounts
+// without a time zone fall back to that zone, or to the server's local zone
+// when no account has one.
+func resolveUserZones(ctx context.Context, provider CalendarProvider, accounts []string, override string) (userZones, error) {
+	zones := userZones{accounts: make(map[string]*time.Location, len(accounts)), firstDay: time.Monday}
+
+	settings := accountSettings(ctx, provider, accounts)
+	for _, acc := range accounts {
+		if day, ok := settingsWeekdays[settings[acc]["weekStart"]]; ok {
+			zones.firstDay = day
+			break
+		}
+	}
+
+	if override != "" {
+		loc, err := time.LoadLocation(override)
+		if err != nil {
+			return userZones{}, fmt.Errorf("invalid time_zone '%s': %w", override, err)
+		}
+		zones.display = loc
+		for _, acc := range accounts {
+			zones.accounts[acc] = loc
+		}
+		return zones, nil
+	}
+
+	for _, acc := range accounts {
+		loc, err := accountZone(provider, acc, settings[acc])
+		if err != nil {
+			return userZones{}, err
+		}
+		if loc == nil {
+			continue
+		}
+		zones.accounts[acc] = loc
+		if zones.display == nil {
+			zones.display = loc
+		}
+	}
+
+	if zones.display == nil {
+		zones.display = time.Local
+	}
+	for _, acc := range accounts {
+		if zones.accounts[acc] == nil {
+			zones.accounts[acc] = zones.display
+		}
+	}
+	return zones, nil
+}
+
+// accountSettings returns the Google Calendar settings of the accounts,
+// queried concurrently. The settings are a best-effort fallback: accounts
+// that cannot be reached are left out, and reported by the tool itself.
+func accountSettings(ctx context.Context, provider CalendarProvider, accounts []string) map[string]map[string]string {
+	type result struct {
+		account  string
+		settings map[string]string
+	}
+	results, _, _ := forEachAccount(ctx, accounts, func(ctx context.Context, acc string) (result, error) {
+		settings, err := provider.Settings(ctx, acc)
+		return result{account: acc, settings: settings}, err
+	})
+
+	settings := make(map[string]map[string]string, len(results))
+	for _, res := range results {
+		settings[res.account] = res.settings
+	}
+	return settings
+}
+
+// accountZone returns the configured time zone of an account, else the time
+// zone of its settings, or nil if neither is known
+func accountZone(provider CalendarProvider, account string, settings map[string]string) (*time.Location, error) {
+	config, err := provider.AccountConfig(account)
+	if err != nil {
+		return nil, err
+	}
+	if config.TimeZone != "" {
+		loc, err := time.LoadLocation(config.TimeZone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time_zone '%s' configured for account '%s': %w", config.TimeZone, account, err)
+		}
+		return loc, nil
+	}
+
+	if settings["timezone"] == "" {
+		return nil, nil
+	}
+	loc, err := time.LoadLocation(settings["timezone"])
+	if err != nil {
+		return nil, nil
+	}
+	return loc, nil
+}
+
+// localizeEvent shows the times of a timed event in the display zone and
+// anchors the dates of an all-day event at midnight in its account's zone, so
+// that the date the user sees is the date of the event
+func (z userZones) localizeEvent(ev *Event) {
+	localize := func(t time.Time) time.Time {
+		if t.IsZero() {
+			return t
+		}
+		if ev.AllDay {
+			y, m, d := t.Date()
+			return time.Date(y, m, d, 0, 0, 0, 0, z.account(ev.Account))
+		}
+		return t.In(z.display)
+	}
+
+	ev.Start = localize(ev.Start)
+	ev.End = localize(ev.End)
+	if ev.OriginalStartTime != nil {
+		original := localize(*ev.OriginalStartTime)
+		ev.OriginalStartTime = &original
+	}
+	if d := ev.Details; d != nil {
+		d.Created = localizeTimestamp(d.Created, z.display)
+		d.Updated = localizeTimestamp(d.Updated, z.display)
+	}
+}
+
+// account returns the zone of an account, defaulting to the display zone
+func (z userZones) account(account string) *time.Location {
+	if loc := z.accounts[account]; loc != nil {
+		return loc
+	}
+	return z.display
+}
+
+// localizeTimestamp shows a timestamp in loc, leaving zero times alone
+func localizeTimestamp(t time.Time, loc *time.Location) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return t.In(loc)
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestResolveUserZones verifies the override, per-account and fallback time zones
+func TestResolveUserZones(t *testing.T) {
+	ctx := context.Background()
+	provider := &configuredZones{zones: map[string]string{"work": "America/New_York", "personal": "Europe/Paris"}}
+
+	zones, err := resolveUserZones(ctx, provider, []string{"other", "work", "personal"}, "")
+	if err != nil {
+		t.Fatalf("resolveUserZones() error: %v", err)
+	}
+	if zones.display.String() != "America/New_York" {
+		t.Errorf("Expected the first configured zone for display, got %s", zones.display)
+	}
+	if got := zones.account("personal").String(); got != "Europe/Paris" {
+		t.Errorf("Expected the personal zone, got %s", got)
+	}
+	if got := zones.account("other").String(); got != "America/New_York" {
+		t.Errorf("Expected an unconfigured account to use the display zone, got %s", got)
+	}
+
+	zones, err = resolveUserZones(ctx, provider, []string{"work", "personal"}, "Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("resolveUserZones() error: %v", err)
+	}
+	if zones.display.String() != "Asia/Tokyo" || zones.account("personal").String() != "Asia/Tokyo" {
+		t.Errorf("Expected the override everywhere, got %+v", zones)
+	}
+
+	if _, err := resolveUserZones(ctx, provider, []string{"work"}, "Mars/Olympus"); err == nil {
+		t.Error("Expected an invalid override to fail")
+	}
+	// Without a configured zone, the zone of the account's settings applies
+	provider.settings = map[string]map[string]string{"other": {"timezone": "Asia/Kolkata"}}
+	zones, err = resolveUserZones(ctx, provider, []string{"other", "work"}, "")
+	if err != nil {
+		t.Fatalf("resolveUserZones() error: %v", err)
+	}
+	if zones.display.String() != "Asia/Kolkata" || zones.account("work").String() != "America/New_York" {
+		t.Errorf("Expected the settings zone for display and the configured zone for work, got %+v", zones)
+	}
+	if zones.firstDay != time.Monday {
+		t.Errorf("Expected weeks to start on Monday by default, got %s", zones.firstDay)
+	}
+
+	// Weeks start on the first day of the settings of the first account that has one
+	provider.settings["work"] = map[string]string{"weekStart": "0"}
+	zones, err = resolveUserZones(ctx, provider, []string{"other", "work"}, "Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("resolveUserZones() error: %v", err)
+	}
+	if zones.firstDay != time.Sunday {
+		t.Errorf("Expected weeks to start on Sunday, got %s", zones.firstDay)
+	}
+
+	provider.zones["work"] = "Nowhere"
+	if _, err := resolveUserZones(ctx, provider, []string{"work"}, ""); err == nil {
+		t.Error("Expected an invalid configured zone to fail")
+	}
+}
+
+// TestLocalizeEvent verifies timed events move to the display zone while
+// all-day events keep their date
+func TestLocalizeEvent(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	zones := userZones{display: newYork, accounts: map[string]*time.Location{"personal": newYork}}
+
+	allDay := Event{
+		Account: "personal",
+		AllDay:  true,
+		Start:   time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC),
+		End:     time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC),
+	}
+	zones.localizeEvent(&allDay)
+	if got := allDay.Start.Format("2006-01-02 15:04 MST"); got != "2026-10-17 00:00 EDT" {
+		t.Errorf("Expected the all-day event to start on Oct 17 in New York, got %s", got)
+	}
+
+	original := time.Date(2026, 10, 17, 14, 0, 0, 0, time.UTC)
+	timed := Event{Account: "personal", Start: original, End: original.Add(time.Hour), OriginalStartTime: &original}
+	zones.localizeEvent(&timed)
+	if got := timed.Start.Format("15:04"); got != "10:00" || !timed.Start.Equal(original) {
+		t.Errorf("Expected 10:00 in New York, got %s", got)
+	}
+	if timed.OriginalStartTime.Location() != newYork {
+		t.Errorf("Expected the original start in New York, got %v", timed.OriginalStartTime)
+	}
+}
+
+// configuredZones is a CalendarProvider whose accounts only carry a time zone
+type configuredZones struct {
+	fakeProvider
+	zones map[string]string
+}
+
+func (c *configuredZones) AccountConfig(account string) (AccountConfig, error) {
+	return AccountConfig{Name: account, TimeZone: c.zones[account]}, nil
+}